@@ -2,32 +2,96 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
+	"io"
 	"log"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	texttemplate "text/template"
+	"time"
 
 	"github.com/aws/jsii-runtime-go"
 	"github.com/qrioso-software/qriososls/internal/assets"
 	"github.com/qrioso-software/qriososls/internal/config"
 	"github.com/qrioso-software/qriososls/internal/engine"
 	"github.com/qrioso-software/qriososls/internal/engine/local"
+	"github.com/qrioso-software/qriososls/internal/progress"
+	"github.com/qrioso-software/qriososls/internal/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // Application constants
 const (
-	defaultConfigPath  = "qrioso-sls.yml" // Default configuration file path
-	defaultServiceName = "qrioso-example" // Default service name
-	defaultStage       = "dev"            // Default deployment stage
-	defaultRegion      = "us-east-1"      // Default AWS region
-	buildDir           = "build"          // Build directory for artifacts
-	cdkOutDir          = "cdk.out"        // CDK output directory for cloud assembly
+	defaultConfigPath  = "qrioso-sls.yml"  // Default configuration file path
+	defaultServiceName = "qrioso-example"  // Default service name
+	defaultStage       = "dev"             // Default deployment stage
+	defaultRegion      = "us-east-1"       // Default AWS region
+	buildDir           = "build"           // Build directory for artifacts
+	cdkOutDir          = "cdk.out"         // CDK output directory for cloud assembly
+	cdkBinEnv          = "QRIOSLS_CDK_BIN" // Env override for the CDK CLI binary
+	samBinEnv          = "QRIOSLS_SAM_BIN" // Env override for the SAM CLI binary
+)
+
+// Process exit codes by failure class, so CI can branch on them
+const (
+	exitGeneric = 1 // Any other failure
+	exitSchema  = 2 // Configuration failed to load or validate
+	exitSynth   = 3 // Configuration is valid but synthesis failed
+	exitStrict  = 4 // Strict mode: warnings reported as errors
+)
+
+// Process exit codes for cdk/sam failures (see classifyToolFailure)
+const (
+	exitToolMissing = 5 // cdk or sam CLI not found
+	exitAborted     = 6 // Changes not approved, or the run was interrupted
+	exitRollback    = 7 // CloudFormation rolled the stack back
+	exitToolFailed  = 8 // cdk or sam failed for any other reason
+)
+
+// Output markers that tell cdk failures apart: cdk exits 1 for all of them
+var toolFailures = []struct {
+	marker string
+	code   int
+	reason string
+}{
+	{"Aborted by user", exitAborted, "aborted: changes were not approved"},
+	{"ROLLBACK_COMPLETE", exitRollback, "failed and the stack was rolled back"},
+	{"ROLLBACK_IN_PROGRESS", exitRollback, "failed and the stack is rolling back"},
+	{"ROLLBACK_FAILED", exitRollback, "failed and the stack rollback failed"},
+}
+
+// Bytes of subprocess stderr kept to classify a failure
+const toolOutputTail = 64 * 1024
+
+// Saved templates for diff --since
+const snapshotsDir = ".qriosls/snapshots"
+
+// Set by deploy --watch so the cdkapp run by cdk builds the functions before each synth
+const buildOnSynthEnv = "QRIOSLS_BUILD_ON_SYNTH"
+
+// Allowed snapshot names for --save/--since
+var reSnapshotName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// CloudFormation parameter override for deploy --parameter: [Stack:]Key=Value
+var reParameterOverride = regexp.MustCompile(`^([A-Za-z0-9-]+:)?[A-Za-z0-9]+=`)
+
+// Validation levels for the validate command
+const (
+	levelSchema = "schema"
+	levelSynth  = "synth"
+	levelStrict = "strict"
 )
 
 var version = "dev"
@@ -36,18 +100,56 @@ var date = "unknown"
 
 // App represents the main application structure holding configuration and state
 type App struct {
-	configPath      string // Path to the configuration file
-	awsProfile      string // AWS profile to use for deployment
-	requireApproval string // CDK require-approval setting
-	service         string // Service name for init command
-	stage           string // Stage name for init command
-	region          string // AWS region for init command
-	RootPath        string // Root directory of the project
+	configPath      string   // Path to the configuration file
+	configOverlays  []string // Extra config files deep-merged over configPath, in order
+	preview         string   // Preview id appended to stack, API, function and bucket names
+	exclusively     []string // Only synthesize these functions (empty = all)
+	awsProfile      string   // AWS profile to use for deployment
+	awsRegion       string   // AWS region override for AWS/CDK calls
+	requireApproval string   // CDK require-approval setting
+	cdkBin          string   // CDK CLI binary (name in PATH or path)
+	samBin          string   // SAM CLI binary used by local mode (name in PATH or path)
+	service         string   // Service name for init command
+	stage           string   // Stage name for init command
+	region          string   // AWS region for init command
+	format          string   // Output format for config show (yaml|json)
+	skipInstall     bool     // Skip npm/pip install in local mode
+	trace           bool     // Log local requests through a tracing proxy
+	traceBodies     bool     // Include request/response bodies in the trace log
+	verbose         bool     // Show raw build output in local mode
+	docsOutput      string   // Output path for the docs command (empty = stdout)
+	outputsFile     string   // File where cdk deploy writes stack outputs
+	jsonEvents      bool     // Emit newline-delimited JSON progress events instead of logs
+	allStages       bool     // Plan every stage declared in the config
+	planFrom        string   // Baseline stage plan compares against
+	validateLevel   string   // Validation level: schema|synth|strict
+	RootPath        string   // Root directory of the project
+
+	watchPoll    bool          // Poll file mtimes instead of fsnotify in local mode
+	failFast     bool          // Stop local builds at the first failure
+	dryRun       bool          // Print the migrated config instead of writing it
+	saveAs       string        // Snapshot name the synthesized template is saved under
+	since        string        // Snapshot name diff compares against instead of the live stack
+	watch        bool          // Keep deploying (hotswap) on file changes
+	force        bool          // Skip the destroy confirmation prompt / overwrite the config on init
+	clean        bool          // Remove build/ and the cloud assembly on init
+	autoSynth    bool          // Re-synthesize the local stack when the config changes
+	port         int           // Port the local API is served on
+	function     string        // Function (config key) the invoke command runs
+	payload      string        // Event for invoke: JSON file path or inline JSON
+	parameters   []string      // CloudFormation parameter overrides for deploy (Key=Value)
+	outputDir    string        // Cloud assembly directory (default cdk.out)
+	jsonOutput   bool          // Print machine-readable JSON instead of a table
+	follow       bool          // Keep streaming new log events (logs command)
+	pollInterval time.Duration // Polling interval for --watch-poll
+
+	cfg *config.ServerlessConfig // Resolved configuration, loaded once per invocation
 }
 
 // main is the application entry point
 // Initializes jsii runtime and runs the application
 func main() {
+	engine.ToolVersion = version
 
 	defer func() {
 		if err := recover(); err != nil {
@@ -68,7 +170,8 @@ func main() {
 
 	if err := app.Run(); err != nil {
 		log.Printf("Error: %v", err)
-		os.Exit(1)
+		progress.Emit(progress.Event{Phase: "qriosls", Status: progress.StatusFailed, Error: err.Error()})
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -86,14 +189,25 @@ func (a *App) setupRootCommand() *cobra.Command {
 		Use:   "qriosls",
 		Short: "Qrioso Sls: YAML -> AWS CDK (Go)",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if a.jsonEvents {
+				progress.EnableJSON(os.Stdout)
+			}
 			return a.setupViper()
 		},
 	}
 
 	// Global flags available for all commands
 	root.PersistentFlags().StringVarP(&a.configPath, "config", "c", defaultConfigPath, "Configuration file path")
+	root.PersistentFlags().StringArrayVar(&a.configOverlays, "config-overlay", nil, "Config file merged over --config (repeatable, applied in order)")
+	root.PersistentFlags().StringVar(&a.preview, "preview", "", "Preview id (e.g. pr-123) appended to stack, API, function and stack-owned bucket names")
+	root.PersistentFlags().StringSliceVar(&a.exclusively, "exclusively", nil, "Only synthesize these functions, pruning the others' routes (comma-separated or repeatable)")
 	root.PersistentFlags().StringVar(&a.awsProfile, "profile", "", "AWS profile name")
+	root.PersistentFlags().StringVar(&a.awsRegion, "region", "", "AWS region (defaults to provider.region)")
+	root.PersistentFlags().BoolVar(&a.jsonEvents, "json-events", false, "Stream newline-delimited JSON progress events (for CI)")
 	root.PersistentFlags().StringVar(&a.requireApproval, "require-approval", "", "CDK approval level: never|any-change|broadening")
+	root.PersistentFlags().StringVar(&a.cdkBin, "cdk-bin", "", "CDK CLI binary to use (default $"+cdkBinEnv+" or cdk in PATH)")
+	root.PersistentFlags().StringVar(&a.samBin, "sam-bin", "", "SAM CLI binary for local mode (default $"+samBinEnv+" or sam in PATH)")
+	root.PersistentFlags().StringVar(&a.outputDir, "output-dir", cdkOutDir, "Cloud assembly directory, so parallel builds in one workspace don't share cdk.out")
 
 	// Register all subcommands
 	root.AddCommand(
@@ -102,10 +216,21 @@ func (a *App) setupRootCommand() *cobra.Command {
 		a.synthCommand(),
 		a.deployCommand(),
 		a.diffCommand(),
+		a.destroyCommand(),
 		a.doctorCommand(),
 		a.cdkAppCommand(),
 		a.versionCommand(),
 		a.localCommand(),
+		a.invokeCommand(),
+		a.configCommand(),
+		a.docsCommand(),
+		a.planCommand(),
+		a.testCommand(),
+		a.migrateCommand(),
+		a.permissionsCommand(),
+		a.functionsCommand(),
+		a.outputsCommand(),
+		a.logsCommand(),
 	)
 
 	return root
@@ -139,6 +264,8 @@ func (a *App) initCommand() *cobra.Command {
 	cmd.Flags().StringVar(&a.service, "service", defaultServiceName, "Service name")
 	cmd.Flags().StringVar(&a.stage, "stage", defaultStage, "Deployment stage (dev|stg|prod)")
 	cmd.Flags().StringVar(&a.region, "region", defaultRegion, "AWS region")
+	cmd.Flags().BoolVar(&a.force, "force", false, "Overwrite the config file if it already exists")
+	cmd.Flags().BoolVar(&a.clean, "clean", false, "Remove the build directory and the cloud assembly before scaffolding")
 
 	return cmd
 }
@@ -148,8 +275,8 @@ func (a *App) initCommand() *cobra.Command {
 // Returns: error if template creation or file operations fail
 // Output: Creates configuration file and build directory
 func (a *App) runInit(cmd *cobra.Command, args []string) error {
-	if _, err := os.Stat(a.configPath); err == nil {
-		return fmt.Errorf("file %s already exists in directory", a.configPath)
+	if _, err := os.Stat(a.configPath); err == nil && !a.force {
+		return fmt.Errorf("file %s already exists in directory (use --force to overwrite it)", a.configPath)
 	}
 
 	file, err := assets.Templates.ReadFile("templates/qrioso-sls.tmpl.yml")
@@ -157,12 +284,7 @@ func (a *App) runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error reading template: %w", err)
 	}
 
-	t := template.Must(template.New("srv").Parse(string(file)))
-	f, err := os.Create(a.configPath)
-	if err != nil {
-		return fmt.Errorf("error creating config file: %w", err)
-	}
-	defer f.Close()
+	t := texttemplate.Must(texttemplate.New("srv").Parse(string(file)))
 
 	data := struct {
 		Service string
@@ -170,10 +292,22 @@ func (a *App) runInit(cmd *cobra.Command, args []string) error {
 		Region  string
 	}{a.service, a.stage, a.region}
 
-	if err := t.Execute(f, data); err != nil {
+	// Rendered before writing so a failure with --force doesn't leave a truncated config
+	var rendered bytes.Buffer
+	if err := t.Execute(&rendered, data); err != nil {
 		return fmt.Errorf("error executing template: %w", err)
 	}
 
+	if a.clean {
+		if err := cleanGeneratedDirs(buildDir, a.outputDir); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(a.configPath, rendered.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error creating config file: %w", err)
+	}
+
 	if err := os.MkdirAll(buildDir, 0755); err != nil {
 		return fmt.Errorf("error creating build directory: %w", err)
 	}
@@ -182,14 +316,57 @@ func (a *App) runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// cleanGeneratedDirs removes directories holding generated files (build output, cloud assembly)
+// Input: dirs - directories to remove; missing ones are skipped
+// Returns: error if a directory can't be removed or is not inside the working directory
+func cleanGeneratedDirs(dirs ...string) error {
+	// --output-dir . (or ../x) would otherwise wipe the project itself; checked before removing anything
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(cwd, abs); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to remove %s: only directories inside the project are cleaned", dir)
+		}
+	}
+
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("error removing %s: %w", dir, err)
+		}
+		log.Printf("🧹 Removed %s/", dir)
+	}
+	return nil
+}
+
 // validateCommand creates the 'validate' subcommand for configuration validation
 // Returns: *cobra.Command - configured validate command
 func (a *App) validateCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "validate",
 		Short: "Validate the configuration file",
-		RunE:  a.runValidate,
+		Long: `Validate the configuration file.
+
+Levels:
+  schema  load and validate the config only (fast, for pre-commit hooks)
+  synth   schema + in-process CDK synthesis into a temporary directory
+  strict  synth + treat warnings as errors
+
+Exit codes: 2 schema failure, 3 synth failure, 4 warnings in strict mode.`,
+		RunE: a.runValidate,
 	}
+
+	cmd.Flags().StringVar(&a.validateLevel, "level", levelSchema, "Validation level: schema|synth|strict")
+
+	return cmd
 }
 
 // runValidate executes configuration validation
@@ -197,16 +374,42 @@ func (a *App) validateCommand() *cobra.Command {
 // Returns: error if configuration is invalid or cannot be loaded
 // Output: Validation success/failure message
 func (a *App) runValidate(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load(a.configPath)
+	switch a.validateLevel {
+	case levelSchema, levelSynth, levelStrict:
+	default:
+		return fmt.Errorf("unsupported level '%s' (expected schema|synth|strict)", a.validateLevel)
+	}
+
+	step := progress.Start("validate", "")
+	cfg, err := a.loadValidConfig()
 	if err != nil {
-		return fmt.Errorf("error loading config: %w", err)
+		return withExitCode(exitSchema, step.Done(err))
 	}
+	step.Done(nil)
 
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+	warnings := cfg.Warnings()
+	for _, warning := range warnings {
+		log.Printf("⚠️ %s", warning)
 	}
 
-	log.Println("✅ Configuration valid")
+	if a.validateLevel != levelSchema {
+		outdir, err := os.MkdirTemp("", "qriosls-validate-")
+		if err != nil {
+			return fmt.Errorf("error creating temporary directory: %w", err)
+		}
+		defer os.RemoveAll(outdir)
+
+		step := progress.Start("synth", "")
+		if _, err := engine.Synth(cfg, outdir); step.Done(err) != nil {
+			return withExitCode(exitSynth, err)
+		}
+	}
+
+	if a.validateLevel == levelStrict && len(warnings) > 0 {
+		return withExitCode(exitStrict, fmt.Errorf("%d warning(s) reported in strict mode", len(warnings)))
+	}
+
+	log.Printf("✅ Configuration valid (level: %s)", a.validateLevel)
 	return nil
 }
 
@@ -225,27 +428,57 @@ func (a *App) cdkAppCommand() *cobra.Command {
 // Returns: error if configuration validation or synthesis fails
 // Output: Generates cloud assembly in specified output directory
 func (a *App) runCdkApp(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load(a.configPath)
+	cfg, err := a.loadValidConfig()
 	if err != nil {
-		return fmt.Errorf("error loading config: %w", err)
+		return err
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+	// deploy --watch: cdk re-runs the app on each change, so build everything here.
+	// Otherwise only build dirs (Python dependencies) are built: they are the asset
+	buildAll := os.Getenv(buildOnSynthEnv) != ""
+	if buildAll || hasBuildDirs(cfg) {
+		runner, err := local.NewLocalRunner(cfg, local.Options{Verbose: a.verbose})
+		if err != nil {
+			return fmt.Errorf("error creating local runner: %w", err)
+		}
+		if buildAll {
+			err = runner.Build()
+		} else {
+			err = runner.BuildDirs()
+		}
+		runner.Stop()
+		if err != nil {
+			return err
+		}
 	}
 
 	outdir := os.Getenv("CDK_OUTDIR")
-	return engine.Synth(cfg, outdir)
+	_, err = engine.Synth(cfg, outdir)
+	return err
+}
+
+// hasBuildDirs reports whether any active function is packaged from its build dir
+func hasBuildDirs(cfg *config.ServerlessConfig) bool {
+	for funcName := range cfg.ActiveFunctions() {
+		if cfg.UsesBuildDir(funcName) {
+			return true
+		}
+	}
+	return false
 }
 
 // synthCommand creates the 'synth' subcommand for CDK synthesis
 // Returns: *cobra.Command - configured synth command
 func (a *App) synthCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "synth",
 		Short: "Generate cdk.out (Cloud Assembly)",
 		RunE:  a.runSynth,
 	}
+
+	cmd.Flags().StringVar(&a.saveAs, "save", "", "Save the synthesized template as a snapshot for diff --since")
+
+	return cmd
 }
 
 // runSynth executes CDK synthesis via external CDK CLI
@@ -253,30 +486,36 @@ func (a *App) synthCommand() *cobra.Command {
 // Returns: error if CDK CLI not found or synthesis fails
 // Output: Cloud assembly in cdk.out directory
 func (a *App) runSynth(cmd *cobra.Command, args []string) error {
-	if _, err := a.checkCdkInstalled(); err != nil {
+	cdkPath, err := a.checkCdkInstalled()
+	if err != nil {
 		return err
 	}
 
-	cfg, err := config.Load(a.configPath)
+	cfg, err := a.loadValidConfig()
 	if err != nil {
-		return fmt.Errorf("error loading config: %w", err)
+		return err
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+	if err := validateSnapshotName(a.saveAs); err != nil {
+		return err
+	}
+	if err := checkWritableDir(a.outputPath()); err != nil {
+		return fmt.Errorf("invalid --output-dir: %w", err)
 	}
 
-	ex := exec.Command("cdk", "synth", "--output", cdkOutDir)
-	ex.Env = a.prepareCdkEnvironment()
-	ex.Stdout = os.Stdout
+	cmdArgs := append([]string{"synth", "--output", a.outputPath()}, a.cdkProfileArgs()...)
+	ex := exec.Command(cdkPath, cmdArgs...)
+	ex.Env = a.prepareCdkEnvironment(cfg)
+	ex.Stdout = progress.Stdout()
 	ex.Stderr = os.Stderr
 
-	if err := ex.Run(); err != nil {
-		return fmt.Errorf("error in cdk synth: %w", err)
+	step := progress.Start("synth", "")
+	if err := step.Done(runTool("cdk synth", ex)); err != nil {
+		return err
 	}
 
-	log.Printf("✅ Synthesis complete in %s/", cdkOutDir)
-	return nil
+	log.Printf("✅ Synthesis complete in %s/", a.outputDir)
+	return a.saveSnapshot(cfg)
 }
 
 // deployCommand creates the 'deploy' subcommand for infrastructure deployment
@@ -285,9 +524,21 @@ func (a *App) deployCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "deploy",
 		Short: "Deploy using CDK CLI",
-		RunE:  a.runDeploy,
+		Long: `Deploy using CDK CLI.
+
+With --watch, qriosls wraps cdk deploy --watch: after the first deploy it keeps
+watching the function code and config, rebuilds the functions and hotswaps the
+changes into the stack until interrupted. It targets a real AWS account, so use
+it only against a dev stage: hotswap skips CloudFormation (the stack drifts) and
+every deploy runs real resources that can incur cost.`,
+		RunE: a.runDeploy,
 	}
 
+	cmd.Flags().StringVar(&a.outputsFile, "outputs-file", "", "Write CloudFormation stack outputs to this JSON file")
+	cmd.Flags().StringVar(&a.saveAs, "save", "", "Save the deployed template as a snapshot for diff --since")
+	cmd.Flags().StringArrayVar(&a.parameters, "parameter", nil, "CloudFormation parameter override as Key=Value (repeatable)")
+	cmd.Flags().BoolVar(&a.watch, "watch", false, "Rebuild and hotswap changes to the deployed dev stack until interrupted (dev only, incurs AWS cost)")
+
 	return cmd
 }
 
@@ -296,44 +547,87 @@ func (a *App) deployCommand() *cobra.Command {
 // Returns: error if deployment fails or prerequisites not met
 // Output: Deploys AWS infrastructure resources
 func (a *App) runDeploy(cmd *cobra.Command, args []string) error {
-	if _, err := a.checkCdkInstalled(); err != nil {
+	// A reduced stack would delete the excluded functions from AWS
+	if len(a.exclusively) > 0 {
+		return fmt.Errorf("--exclusively is not supported by deploy: functions left out would be deleted from the stack")
+	}
+
+	cdkPath, err := a.checkCdkInstalled()
+	if err != nil {
 		return err
 	}
 
-	cfg, err := config.Load(a.configPath)
+	cfg, err := a.loadValidConfig()
 	if err != nil {
-		return fmt.Errorf("error loading config: %w", err)
+		return err
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+	if err := validateSnapshotName(a.saveAs); err != nil {
+		return err
+	}
+	if a.watch && a.saveAs != "" {
+		return fmt.Errorf("--save cannot be combined with --watch")
 	}
 
-	cmdArgs := []string{"deploy"}
-	if a.requireApproval != "" {
-		cmdArgs = append(cmdArgs, "--require-approval", a.requireApproval)
+	if err := checkWritableDir(a.outputPath()); err != nil {
+		return fmt.Errorf("invalid --output-dir: %w", err)
 	}
-	if a.awsProfile != "" {
-		cmdArgs = append(cmdArgs, "--profile", a.awsProfile)
+
+	cmdArgs, err := a.deployArgs()
+	if err != nil {
+		return err
 	}
 
-	ex := exec.Command("cdk", cmdArgs...)
-	ex.Env = a.prepareCdkEnvironment()
-	ex.Stdout = os.Stdout
+	ex := exec.Command(cdkPath, cmdArgs...)
+	ex.Env = a.prepareCdkEnvironment(cfg)
+	ex.Stdout = progress.Stdout()
 	ex.Stderr = os.Stderr
 
-	log.Printf("🚀 Executing: cdk %s", strings.Join(cmdArgs, " "))
-	return ex.Run()
+	if a.watch {
+		return a.runDeployWatch(cfg, ex)
+	}
+
+	log.Printf("🚀 Executing: %s %s", cdkPath, strings.Join(cmdArgs, " "))
+	if err := progress.Start("deploy", "").Done(runTool("cdk deploy", ex)); err != nil {
+		return err
+	}
+	return a.saveSnapshot(cfg)
+}
+
+// deployArgs builds the cdk deploy arguments from the deploy flags
+// Returns: ([]string, error) - cdk arguments, error if --outputs-file or a --parameter is invalid
+func (a *App) deployArgs() ([]string, error) {
+	cmdArgs := []string{"deploy", "--output", a.outputPath()}
+	if a.requireApproval != "" {
+		cmdArgs = append(cmdArgs, "--require-approval", a.requireApproval)
+	}
+	if a.outputsFile != "" {
+		if err := checkWritable(a.outputsFile); err != nil {
+			return nil, fmt.Errorf("invalid --outputs-file: %w", err)
+		}
+		cmdArgs = append(cmdArgs, "--outputs-file", a.outputsFile)
+	}
+	for _, parameter := range a.parameters {
+		if !reParameterOverride.MatchString(parameter) {
+			return nil, fmt.Errorf("invalid --parameter '%s': expected Key=Value (Key alphanumeric, optionally Stack:Key)", parameter)
+		}
+		cmdArgs = append(cmdArgs, "--parameters", parameter)
+	}
+	return append(cmdArgs, a.cdkProfileArgs()...), nil
 }
 
 // diffCommand creates the 'diff' subcommand for infrastructure changes comparison
 // Returns: *cobra.Command - configured diff command
 func (a *App) diffCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "diff",
 		Short: "Compare changes with CDK CLI",
 		RunE:  a.runDiff,
 	}
+
+	cmd.Flags().StringVar(&a.since, "since", "", "Compare against a snapshot saved with synth/deploy --save instead of the live stack")
+
+	return cmd
 }
 
 // runDiff executes CDK diff to show infrastructure changes
@@ -341,25 +635,108 @@ func (a *App) diffCommand() *cobra.Command {
 // Returns: error if diff execution fails
 // Output: Displays infrastructure changes between current and proposed state
 func (a *App) runDiff(cmd *cobra.Command, args []string) error {
-	if _, err := a.checkCdkInstalled(); err != nil {
+	cdkPath, err := a.checkCdkInstalled()
+	if err != nil {
 		return err
 	}
 
-	cfg, err := config.Load(a.configPath)
+	cfg, err := a.loadValidConfig()
 	if err != nil {
-		return fmt.Errorf("error loading config: %w", err)
+		return err
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+	if err := checkWritableDir(a.outputPath()); err != nil {
+		return fmt.Errorf("invalid --output-dir: %w", err)
 	}
 
-	ex := exec.Command("cdk", "diff")
-	ex.Env = a.prepareCdkEnvironment()
-	ex.Stdout = os.Stdout
+	cmdArgs := []string{"diff", "--output", a.outputPath()}
+	if a.since != "" {
+		if err := validateSnapshotName(a.since); err != nil {
+			return err
+		}
+		snapshot := snapshotPath(a.since)
+		if _, err := os.Stat(snapshot); err != nil {
+			return fmt.Errorf("snapshot '%s' not found at %s (save one with synth --save %s)", a.since, snapshot, a.since)
+		}
+		cmdArgs = append(cmdArgs, "--template", snapshot)
+	}
+	cmdArgs = append(cmdArgs, a.cdkProfileArgs()...)
+	ex := exec.Command(cdkPath, cmdArgs...)
+	ex.Env = a.prepareCdkEnvironment(cfg)
+	ex.Stdout = progress.Stdout()
+	ex.Stderr = os.Stderr
+
+	return progress.Start("diff", "").Done(runTool("cdk diff", ex))
+}
+
+// destroyCommand creates the 'destroy' subcommand that tears down the deployed stack
+// Returns: *cobra.Command - configured destroy command
+func (a *App) destroyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "destroy",
+		Aliases: []string{"remove"},
+		Short:   "Destroy the deployed stack using CDK CLI",
+		RunE:    a.runDestroy,
+	}
+
+	cmd.Flags().BoolVar(&a.force, "force", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+// runDestroy executes CDK destroy for the configured stack
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if destroy fails or prerequisites not met
+// Output: Deletes the stack's AWS resources
+func (a *App) runDestroy(cmd *cobra.Command, args []string) error {
+	// destroy removes the whole stack: --exclusively would suggest otherwise
+	if len(a.exclusively) > 0 {
+		return fmt.Errorf("--exclusively is not supported by destroy: it always removes the whole stack")
+	}
+
+	cdkPath, err := a.checkCdkInstalled()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := a.loadValidConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := checkWritableDir(a.outputPath()); err != nil {
+		return fmt.Errorf("invalid --output-dir: %w", err)
+	}
+
+	// Confirmation is asked here, naming the stack and stage, rather than by cdk
+	if !a.force && !confirm(fmt.Sprintf("Destroy stack '%s' (stage %s) and all its resources?", cfg.StackName(), cfg.Stage)) {
+		log.Printf("Destroy cancelled")
+		return nil
+	}
+
+	cmdArgs := []string{"destroy", "--output", a.outputPath(), "--force"}
+	cmdArgs = append(cmdArgs, a.cdkProfileArgs()...)
+
+	ex := exec.Command(cdkPath, cmdArgs...)
+	ex.Env = a.prepareCdkEnvironment(cfg)
+	ex.Stdout = progress.Stdout()
 	ex.Stderr = os.Stderr
 
-	return ex.Run()
+	log.Printf("🗑️ Destroying stack '%s': %s %s", cfg.StackName(), cdkPath, strings.Join(cmdArgs, " "))
+	return progress.Start("destroy", "").Done(runTool("cdk destroy", ex))
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin
+// Input: question - prompt shown before [y/N]
+// Returns: bool - true only for y/yes; a closed stdin counts as no
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
 }
 
 // doctorCommand creates the 'doctor' subcommand for environment verification
@@ -382,7 +759,10 @@ func (a *App) runDoctor(cmd *cobra.Command, args []string) {
 	}{
 		{"Node.js", a.checkNode},
 		{"CDK CLI", a.checkCdk},
+		{"SAM CLI", a.checkSam},
+		{"Docker", a.checkDocker},
 		{"Go", a.checkGo},
+		{"jsii runtime", engine.CheckJsiiRuntime},
 		{"AWS Credentials", a.checkAwsCredentials},
 	}
 
@@ -407,62 +787,1273 @@ func (a *App) versionCommand() *cobra.Command {
 	}
 }
 
-func (a *App) localCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "local",
-		Short: "Run locally with hot reload",
-		RunE:  a.runLocal,
+// configCommand creates the 'config' subcommand group for configuration inspection
+// Returns: *cobra.Command - configured config command with its subcommands
+func (a *App) configCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
 	}
+
+	show := &cobra.Command{
+		Use:     "show",
+		Aliases: []string{"render"},
+		Short:   "Print the fully-resolved configuration (same resolution as synth)",
+		RunE:    a.runConfigShow,
+	}
+	show.Flags().StringVar(&a.format, "format", "yaml", "Output format: yaml|json")
+
+	cmd.AddCommand(show)
+	return cmd
 }
 
-func (a *App) runLocal(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load(a.configPath)
+// runConfigShow prints the configuration after variable resolution
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if the configuration cannot be loaded or encoded
+// Output: Resolved configuration in the requested format on stdout
+func (a *App) runConfigShow(cmd *cobra.Command, args []string) error {
+	cfg, err := a.loadConfig()
 	if err != nil {
-		return fmt.Errorf("error loading config: %w", err)
+		return err
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
 	}
 
-	cfg.RootPath = a.RootPath
-	runner, err := local.NewLocalRunner(cfg)
+	switch a.format {
+	case "yaml":
+	case "json":
+		// Re-decode the YAML so JSON output uses the same field names
+		var doc interface{}
+		if err := yaml.Unmarshal(out, &doc); err != nil {
+			return fmt.Errorf("error encoding config: %w", err)
+		}
+		if out, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return fmt.Errorf("error encoding config: %w", err)
+		}
+		out = append(out, '\n')
+	default:
+		return fmt.Errorf("unsupported format '%s' (expected yaml|json)", a.format)
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// functionInfo is one entry of the functions list inventory
+type functionInfo struct {
+	Name         string   `json:"name"`
+	FunctionName string   `json:"functionName"`
+	Runtime      string   `json:"runtime"`
+	MemorySize   int      `json:"memorySize"`
+	Timeout      int      `json:"timeout"`
+	Handler      string   `json:"handler"`
+	Code         string   `json:"code"`
+	Events       []string `json:"events"`
+}
+
+// functionsCommand creates the 'functions' command group for the function inventory
+// Returns: *cobra.Command - configured functions command
+func (a *App) functionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "functions",
+		Short: "Inspect the functions of the configuration",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the functions deployed in the stage, with defaults and variables applied",
+		RunE:  a.runFunctionsList,
+	}
+	list.Flags().BoolVar(&a.jsonOutput, "json", false, "Print the inventory as JSON")
+
+	cmd.AddCommand(list)
+	return cmd
+}
+
+// runFunctionsList prints the active functions as resolved for synth
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if the configuration cannot be loaded or encoded
+// Output: Table (or JSON array with --json) of functions on stdout, sorted by name
+func (a *App) runFunctionsList(cmd *cobra.Command, args []string) error {
+	cfg, err := a.loadValidConfig()
 	if err != nil {
-		return fmt.Errorf("error creating local runner: %w", err)
+		return err
 	}
 
-	defer runner.Stop()
-	return runner.Start()
+	active := cfg.ActiveFunctions()
+	functions := make([]functionInfo, 0, len(active))
+	for _, name := range config.SortedFunctionNames(active) {
+		fn := active[name]
+		functions = append(functions, functionInfo{
+			Name:         name,
+			FunctionName: fn.FunctionName,
+			Runtime:      config.CanonicalRuntime(fn.Runtime),
+			MemorySize:   fn.MemorySize,
+			Timeout:      fn.Timeout,
+			Handler:      fn.Handler,
+			Code:         cfg.FunctionAssetPath(name),
+			Events:       eventSummaries(fn),
+		})
+	}
+
+	if a.jsonOutput {
+		out, err := json.MarshalIndent(functions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding functions: %w", err)
+		}
+		_, err = os.Stdout.Write(append(out, '\n'))
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tFUNCTION\tRUNTIME\tMEMORY\tTIMEOUT\tEVENTS")
+	for _, f := range functions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n",
+			f.Name, f.FunctionName, f.Runtime, f.MemorySize, f.Timeout, strings.Join(f.Events, ", "))
+	}
+	return w.Flush()
 }
 
-// HELPER METHODS
+// stackOutput is one CloudFormation output as returned by describe-stacks
+type stackOutput struct {
+	Key         string `json:"OutputKey"`
+	Value       string `json:"OutputValue"`
+	Description string `json:"Description,omitempty"`
+}
 
-// checkCdkInstalled verifies if CDK CLI is available in PATH
-// Returns: (string, error) - path to CDK executable if found, error otherwise
-func (a *App) checkCdkInstalled() (string, error) {
-	return exec.LookPath("cdk")
+// outputsCommand creates the 'outputs' subcommand printing the deployed stack's outputs
+// Returns: *cobra.Command - configured outputs command
+func (a *App) outputsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "outputs",
+		Short: "Print the deployed stack's outputs (API URL, function ARNs...)",
+		RunE:  a.runOutputs,
+	}
+
+	cmd.Flags().BoolVar(&a.jsonOutput, "json", false, "Print the outputs as JSON")
+
+	return cmd
 }
 
-// prepareCdkEnvironment prepares environment variables for CDK execution
-// Returns: []string - environment variables array with CDK_APP configured
-func (a *App) prepareCdkEnvironment() []string {
-	env := os.Environ()
-	appCommand := fmt.Sprintf("qriosls cdkapp --config %s", a.configPath)
-	return append(env, "CDK_APP="+appCommand)
+// runOutputs reads the stack outputs with the AWS CLI
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if the AWS CLI is missing or the stack can't be described
+// Output: Table (or JSON array with --json) of outputs on stdout, sorted by key
+func (a *App) runOutputs(cmd *cobra.Command, args []string) error {
+	cfg, err := a.loadValidConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, err := exec.LookPath("aws"); err != nil {
+		return withExitCode(exitToolMissing, fmt.Errorf("AWS CLI not found: outputs reads them with aws cloudformation describe-stacks"))
+	}
+
+	var out bytes.Buffer
+	cmdArgs := append([]string{"cloudformation", "describe-stacks", "--stack-name", cfg.StackName(),
+		"--query", "Stacks[0].Outputs", "--output", "json"}, a.awsCliArgs(cfg)...)
+	ex := exec.Command("aws", cmdArgs...)
+	ex.Stdout = &out
+	ex.Stderr = os.Stderr
+	if err := ex.Run(); err != nil {
+		return fmt.Errorf("error reading the outputs of stack '%s' (is it deployed?): %w", cfg.StackName(), err)
+	}
+
+	var outputs []stackOutput
+	if err := json.Unmarshal(out.Bytes(), &outputs); err != nil {
+		return fmt.Errorf("error parsing stack outputs: %w", err)
+	}
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Key < outputs[j].Key })
+
+	if a.jsonOutput {
+		data, err := json.MarshalIndent(outputs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding outputs: %w", err)
+		}
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE")
+	for _, o := range outputs {
+		fmt.Fprintf(w, "%s\t%s\n", o.Key, o.Value)
+	}
+	return w.Flush()
 }
 
-// checkNode verifies if Node.js is installed and available
-// Returns: error if Node.js is not found in PATH
-func (a *App) checkNode() error {
-	_, err := exec.LookPath("node")
-	return err
+// logsCommand creates the 'logs' subcommand printing a deployed function's CloudWatch logs
+// Returns: *cobra.Command - configured logs command
+func (a *App) logsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print a deployed function's CloudWatch logs (aws logs tail)",
+		RunE:  a.runLogs,
+	}
+
+	cmd.Flags().StringVarP(&a.function, "function", "f", "", "Function whose logs are shown (its key under functions)")
+	cmd.Flags().BoolVar(&a.follow, "follow", false, "Keep streaming new log events until interrupted")
+	cmd.MarkFlagRequired("function")
+
+	return cmd
 }
 
-// checkCdk verifies if AWS CDK CLI is installed and available
-// Returns: error if CDK is not found in PATH
-func (a *App) checkCdk() error {
-	_, err := exec.LookPath("cdk")
-	return err
+// runLogs maps the function key to its deployed name and tails its log group with the AWS CLI
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if the function is unknown, the AWS CLI is missing or the tail fails
+// Output: Log events streamed to stdout
+func (a *App) runLogs(cmd *cobra.Command, args []string) error {
+	cfg, err := a.loadValidConfig()
+	if err != nil {
+		return err
+	}
+
+	fn, ok := cfg.ActiveFunctions()[a.function]
+	if !ok {
+		return fmt.Errorf("function '%s' not found or not deployed in stage '%s'", a.function, cfg.Stage)
+	}
+
+	if _, err := exec.LookPath("aws"); err != nil {
+		return withExitCode(exitToolMissing, fmt.Errorf("AWS CLI not found: logs reads them with aws logs tail"))
+	}
+
+	logGroup := "/aws/lambda/" + util.ResolveVars(fn.FunctionName, cfg.Stage)
+	cmdArgs := []string{"logs", "tail", logGroup}
+	if a.follow {
+		cmdArgs = append(cmdArgs, "--follow")
+	}
+	cmdArgs = append(cmdArgs, a.awsCliArgs(cfg)...)
+
+	log.Printf("📜 Logs of %s (%s)", a.function, logGroup)
+	ex := exec.Command("aws", cmdArgs...)
+	ex.Stdout = os.Stdout
+	ex.Stderr = os.Stderr
+	if err := ex.Run(); err != nil {
+		return fmt.Errorf("error reading the logs of '%s' (is it deployed and invoked at least once?): %w", a.function, err)
+	}
+	return nil
+}
+
+// permissionsCommand creates the 'permissions' subcommand printing the deploy IAM policy
+// Returns: *cobra.Command - configured permissions command
+func (a *App) permissionsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "permissions",
+		Short: "Print the minimal IAM policy needed to deploy this configuration",
+		RunE:  a.runPermissions,
+	}
+}
+
+// runPermissions derives the deploy policy from the features the config uses
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if the configuration is invalid or cannot be encoded
+// Output: JSON IAM policy document on stdout
+func (a *App) runPermissions(cmd *cobra.Command, args []string) error {
+	cfg, err := a.loadValidConfig()
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(engine.RequiredPermissions(cfg), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding policy: %w", err)
+	}
+
+	_, err = os.Stdout.Write(append(out, '\n'))
+	return err
+}
+
+// docsCommand creates the 'docs' subcommand that documents the service endpoints
+// Returns: *cobra.Command - configured docs command
+func (a *App) docsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate a Markdown summary of endpoints and triggers",
+		RunE:  a.runDocs,
+	}
+
+	cmd.Flags().StringVarP(&a.docsOutput, "output", "o", "", "Write to this file instead of stdout")
+
+	return cmd
+}
+
+// runDocs renders the endpoints document from the configuration
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if the configuration cannot be loaded or the document written
+// Output: Markdown document on stdout or in the --output file
+func (a *App) runDocs(cmd *cobra.Command, args []string) error {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	type endpoint struct {
+		Method, Path, Function, Runtime string
+		MemorySize, Timeout             int
+	}
+	type trigger struct {
+		Function, Type, Source string
+	}
+
+	data := struct {
+		Service   string
+		Stage     string
+		Endpoints []endpoint
+		Triggers  []trigger
+	}{Service: cfg.Service, Stage: cfg.Stage}
+
+	for funcName, fn := range cfg.ActiveFunctions() {
+		for _, ev := range fn.Events {
+			if strings.ToUpper(ev.Type) == "HTTP" {
+				data.Endpoints = append(data.Endpoints, endpoint{
+					Method:     strings.ToUpper(ev.Method),
+					Path:       engine.RoutePath(ev),
+					Function:   funcName,
+					Runtime:    fn.Runtime,
+					MemorySize: fn.MemorySize,
+					Timeout:    fn.Timeout,
+				})
+				continue
+			}
+			data.Triggers = append(data.Triggers, trigger{
+				Function: funcName,
+				Type:     ev.Type,
+				Source:   eventSource(ev),
+			})
+		}
+	}
+
+	sort.Slice(data.Endpoints, func(i, j int) bool {
+		if data.Endpoints[i].Path != data.Endpoints[j].Path {
+			return data.Endpoints[i].Path < data.Endpoints[j].Path
+		}
+		return data.Endpoints[i].Method < data.Endpoints[j].Method
+	})
+	sort.Slice(data.Triggers, func(i, j int) bool {
+		return data.Triggers[i].Function < data.Triggers[j].Function
+	})
+
+	file, err := assets.Templates.ReadFile("templates/docs.tmpl.md")
+	if err != nil {
+		return fmt.Errorf("error reading template: %w", err)
+	}
+
+	t, err := texttemplate.New("docs").Parse(string(file))
+	if err != nil {
+		return fmt.Errorf("error parsing template: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+
+	if a.docsOutput == "" {
+		_, err = os.Stdout.Write(out.Bytes())
+		return err
+	}
+
+	if err := os.WriteFile(a.docsOutput, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing docs: %w", err)
+	}
+
+	log.Printf("✅ Docs written to %s", a.docsOutput)
+	return nil
+}
+
+// planCommand creates the 'plan' subcommand showing per-stage differences
+// Returns: *cobra.Command - configured plan command
+func (a *App) planCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the resolved functions per stage and how they differ from a baseline stage",
+		RunE:  a.runPlan,
+	}
+
+	cmd.Flags().BoolVar(&a.allStages, "all-stages", false, "Plan every stage declared in the config")
+	cmd.Flags().StringVar(&a.planFrom, "from", defaultStage, "Baseline stage the differences are computed against")
+
+	return cmd
+}
+
+// runPlan prints the resolved function set of each stage without deploying
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if any stage fails to load or validate
+// Output: Per-stage function table and differences against the baseline stage
+func (a *App) runPlan(cmd *cobra.Command, args []string) error {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	stages := []string{cfg.Stage}
+	if a.allStages {
+		if stages, err = config.StageNames(a.configFiles()...); err != nil {
+			return err
+		}
+	}
+
+	baselineStage, err := a.planBaselineStage(cmd.Flags().Changed("from"))
+	if err != nil {
+		return err
+	}
+
+	baseline, err := config.LoadFiles(a.configFiles(), baselineStage)
+	if err != nil {
+		return fmt.Errorf("error loading stage %s: %w", baselineStage, err)
+	}
+
+	for _, stage := range stages {
+		stageCfg, err := config.LoadFiles(a.configFiles(), stage)
+		if err != nil {
+			return fmt.Errorf("error loading stage %s: %w", stage, err)
+		}
+		if err := stageCfg.Validate(); err != nil {
+			return fmt.Errorf("stage %s: config validation failed: %w", stage, err)
+		}
+
+		active := stageCfg.ActiveFunctions()
+		fmt.Printf("== %s (%d functions)\n", stage, len(active))
+		for _, name := range config.SortedFunctionNames(active) {
+			fn := active[name]
+			fmt.Printf("  %-24s %-40s %-12s %5dMB %4ds  %s\n",
+				name, fn.FunctionName, fn.Runtime, fn.MemorySize, fn.Timeout, strings.Join(eventSummaries(fn), ", "))
+		}
+
+		if stage == baselineStage {
+			fmt.Println()
+			continue
+		}
+
+		changes := planChanges(baseline.ActiveFunctions(), active)
+		if len(changes) == 0 {
+			fmt.Printf("  no differences from %s\n\n", baselineStage)
+			continue
+		}
+		fmt.Printf("  differences from %s:\n", baselineStage)
+		for _, change := range changes {
+			fmt.Printf("    %s\n", change)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// planBaselineStage returns the stage plan compares against (--from, dev by default)
+// Input: explicit - whether --from was given
+// Returns: (string, error) - the baseline stage, error if an explicit --from is not declared in the config
+func (a *App) planBaselineStage(explicit bool) (string, error) {
+	if !explicit {
+		return a.planFrom, nil
+	}
+
+	stages, err := config.StageNames(a.configFiles()...)
+	if err != nil {
+		return "", err
+	}
+	for _, stage := range stages {
+		if stage == a.planFrom {
+			return a.planFrom, nil
+		}
+	}
+	return "", fmt.Errorf("stage '%s' in --from is not declared in the config (stages: %s)", a.planFrom, strings.Join(stages, ", "))
+}
+
+// testCommand creates the 'test' subcommand running every function's unit tests
+// Returns: *cobra.Command - configured test command
+func (a *App) testCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "test",
+		Short: "Run each function's unit tests (go test, npm test, pytest)",
+		RunE:  a.runTest,
+	}
+}
+
+// runTest runs the tests of every active function through its runtime
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if any function's tests fail
+// Output: Pass/fail/skip line per function
+func (a *App) runTest(cmd *cobra.Command, args []string) error {
+	cfg, err := a.loadValidConfig()
+	if err != nil {
+		return err
+	}
+
+	runner, err := local.NewLocalRunner(cfg, local.Options{Verbose: a.verbose})
+	if err != nil {
+		return fmt.Errorf("error creating local runner: %w", err)
+	}
+	defer runner.Stop()
+
+	results, err := runner.RunTests()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			log.Printf("➖ %s (%s): no tests", r.Function, r.Runtime)
+		case r.Err != nil:
+			failed++
+			log.Printf("❌ %s (%s): %v", r.Function, r.Runtime, r.Err)
+		default:
+			log.Printf("✅ %s (%s): passed", r.Function, r.Runtime)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d function(s) failed their tests", failed, len(results))
+	}
+	return nil
+}
+
+// migrateCommand creates the 'migrate' subcommand upgrading the config schema
+// Returns: *cobra.Command - configured migrate command
+func (a *App) migrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade the configuration file to the current schema version",
+		RunE:  a.runMigrate,
+	}
+
+	cmd.Flags().BoolVar(&a.dryRun, "dry-run", false, "Print the migrated configuration instead of writing it")
+
+	return cmd
+}
+
+// runMigrate rewrites --config to the current schema version after validating the result
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if the file cannot be migrated or the result is invalid
+// Output: Migrated file (or stdout with --dry-run) and the applied steps
+func (a *App) runMigrate(cmd *cobra.Command, args []string) error {
+	original, err := os.ReadFile(a.configPath)
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	migrated, steps, err := config.Migrate(original)
+	if err != nil {
+		return withExitCode(exitSchema, err)
+	}
+	if len(steps) == 0 {
+		log.Printf("✅ %s is already at schema version %d", a.configPath, config.CurrentVersion)
+		return nil
+	}
+
+	for _, step := range steps {
+		log.Printf("🔧 %s", step)
+	}
+
+	if err := config.ValidateMigrated(a.configPath, migrated); err != nil {
+		return withExitCode(exitSchema, fmt.Errorf("migrated config is invalid: %w", err))
+	}
+
+	if a.dryRun {
+		_, err := os.Stdout.Write(migrated)
+		return err
+	}
+
+	if err := os.WriteFile(a.configPath, migrated, 0644); err != nil {
+		return fmt.Errorf("error writing config: %w", err)
+	}
+	log.Printf("✅ Migrated %s to schema version %d", a.configPath, config.CurrentVersion)
+	return nil
+}
+
+func (a *App) localCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Run locally with hot reload",
+		RunE:  a.runLocal,
+	}
+
+	cmd.Flags().BoolVar(&a.skipInstall, "skip-install", false, "Skip npm/pip install for Node.js and Python functions")
+	cmd.Flags().BoolVar(&a.trace, "trace", false, "Log each request (method, path, status, duration) through a local proxy")
+	cmd.Flags().BoolVar(&a.traceBodies, "trace-bodies", false, "With --trace, also log request/response bodies (truncated)")
+	cmd.Flags().BoolVarP(&a.verbose, "verbose", "v", false, "Show raw compiler output when a build fails")
+	cmd.Flags().BoolVar(&a.failFast, "fail-fast", true, "Stop at the first build failure (false = build all functions and report every failure)")
+	cmd.Flags().BoolVar(&a.watchPoll, "watch-poll", false, "Detect changes by polling file mtimes (for network mounts and Docker volumes)")
+	cmd.Flags().DurationVar(&a.pollInterval, "poll-interval", time.Second, "Polling interval for --watch-poll")
+	cmd.Flags().IntVar(&a.port, "port", local.DefaultAPIPort, "Port the local API is served on (1024-65535)")
+	cmd.Flags().BoolVar(&a.autoSynth, "auto-synth", false, "Reload the local API when the config changes (default: only warn that routes are stale)")
+
+	return cmd
+}
+
+func (a *App) runLocal(cmd *cobra.Command, args []string) error {
+	// Fail before building anything if SAM can't run
+	samPath, err := a.checkSamInstalled()
+	if err != nil {
+		return err
+	}
+	if err := a.checkDocker(); err != nil {
+		return err
+	}
+
+	cfg, err := a.loadValidConfig()
+	if err != nil {
+		return err
+	}
+
+	runner, err := local.NewLocalRunner(cfg, local.Options{
+		SkipInstall: a.skipInstall,
+		Trace:       a.trace,
+		TraceBodies: a.traceBodies,
+		Verbose:     a.verbose,
+		BuildAll:    !a.failFast,
+		SamBin:      samPath,
+		StartTool:   startTool,
+		Port:        a.port,
+
+		WatchPoll:    a.watchPoll,
+		PollInterval: a.pollInterval,
+
+		OutDir:      a.outputPath(),
+		ConfigFiles: a.configFiles(),
+		AutoSynth:   a.autoSynth,
+		LoadConfig: func() (*config.ServerlessConfig, error) {
+			a.cfg = nil // Read the edited files again
+			return a.loadValidConfig()
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating local runner: %w", err)
+	}
+
+	defer runner.Stop()
+	return runner.Start()
+}
+
+// invokeCommand creates the 'invoke' subcommand that runs one function locally through SAM
+// Returns: *cobra.Command - configured invoke command
+func (a *App) invokeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "invoke",
+		Short: "Invoke a function locally once with sam local invoke",
+		RunE:  a.runInvoke,
+	}
+
+	cmd.Flags().StringVarP(&a.function, "function", "f", "", "Function to invoke (its key under functions)")
+	cmd.Flags().StringVarP(&a.payload, "payload", "p", "", "Event as a JSON file path or inline JSON (default: empty event)")
+	cmd.Flags().BoolVar(&a.skipInstall, "skip-install", false, "Skip npm/pip install for Node.js and Python functions")
+	cmd.Flags().BoolVarP(&a.verbose, "verbose", "v", false, "Show raw compiler output when a build fails")
+	cmd.MarkFlagRequired("function")
+
+	return cmd
+}
+
+// runInvoke builds the function, synthesizes the local stack and invokes it once
+// Input: cmd - the command instance, args - command arguments
+// Returns: error if the payload is invalid, the build fails or the invocation fails
+// Output: Streams the function's logs and response to stdout
+func (a *App) runInvoke(cmd *cobra.Command, args []string) error {
+	samPath, err := a.checkSamInstalled()
+	if err != nil {
+		return err
+	}
+	if err := a.checkDocker(); err != nil {
+		return err
+	}
+
+	cfg, err := a.loadValidConfig()
+	if err != nil {
+		return err
+	}
+
+	eventPath, cleanup, err := invokeEventFile(a.payload)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	runner, err := local.NewLocalRunner(cfg, local.Options{
+		SkipInstall: a.skipInstall,
+		Verbose:     a.verbose,
+		SamBin:      samPath,
+		OutDir:      a.outputPath(),
+		StartTool:   startTool,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating local runner: %w", err)
+	}
+	defer runner.Stop()
+
+	return runner.Invoke(a.function, eventPath)
+}
+
+// invokeEventFile turns --payload into an event file for sam local invoke
+// Input: payload - a JSON file path, inline JSON, or empty
+// Returns: path to the event file ("" = none), a cleanup func for temp files, error if the JSON is invalid
+func invokeEventFile(payload string) (string, func(), error) {
+	noop := func() {}
+	if payload == "" {
+		return "", noop, nil
+	}
+
+	if _, err := os.Stat(payload); err == nil {
+		data, err := os.ReadFile(payload)
+		if err != nil {
+			return "", noop, err
+		}
+		if !json.Valid(data) {
+			return "", noop, fmt.Errorf("payload file %s is not valid JSON", payload)
+		}
+		return payload, noop, nil
+	}
+
+	if !json.Valid([]byte(payload)) {
+		return "", noop, fmt.Errorf("--payload is neither an existing file nor valid JSON")
+	}
+	f, err := os.CreateTemp("", "qriosls-event-*.json")
+	if err != nil {
+		return "", noop, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(payload); err != nil {
+		os.Remove(f.Name())
+		return "", noop, err
+	}
+	return f.Name(), func() { os.Remove(f.Name()) }, nil
+}
+
+// HELPER METHODS
+
+// exitError carries the process exit code for a failure class
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode tags err with a process exit code (nil stays nil)
+// Input: code - exit code, err - underlying error
+// Returns: error - wrapped error carrying the code
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode maps an error to the process exit code
+// Input: err - error returned by the command
+// Returns: int - tagged exit code, or exitGeneric
+func exitCode(err error) int {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return exitGeneric
+}
+
+// toolError is a failed cdk/sam run, classified from its exit status and output
+type toolError struct {
+	tool   string
+	status int // Exit status of the subprocess (-1 if killed by a signal)
+	reason string
+	err    error
+}
+
+func (e *toolError) Error() string {
+	return fmt.Sprintf("%s %s (exit status %d)", e.tool, e.reason, e.status)
+}
+func (e *toolError) Unwrap() error { return e.err }
+
+// runTool runs a cdk/sam subprocess, streaming its stderr while keeping the
+// tail to classify a failure into a toolError with its own exit code
+// Input: tool - name for messages (e.g. "cdk deploy"), ex - prepared command
+// Returns: error tagged with exitAborted, exitRollback, exitToolMissing or exitToolFailed
+func runTool(tool string, ex *exec.Cmd) error {
+	wait, err := startTool(tool, ex)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
+
+// startTool starts a cdk/sam subprocess that may outlive the call (sam local start-api)
+// Input: tool - name for messages, ex - prepared command
+// Returns: wait - blocks until it exits and classifies the failure like runTool; error if it could not start
+func startTool(tool string, ex *exec.Cmd) (func() error, error) {
+	tail := &tailBuffer{limit: toolOutputTail}
+	if ex.Stderr != nil {
+		ex.Stderr = io.MultiWriter(ex.Stderr, tail)
+	} else {
+		ex.Stderr = tail
+	}
+	if err := ex.Start(); err != nil {
+		return nil, classifyToolFailure(tool, err, "")
+	}
+	return func() error {
+		return classifyToolFailure(tool, ex.Wait(), tail.String())
+	}, nil
+}
+
+// classifyToolFailure maps a subprocess error and its output to a toolError
+// Input: tool - name for messages, err - result of Run, output - stderr tail
+// Returns: error tagged with the exit code for its failure class (nil stays nil)
+func classifyToolFailure(tool string, err error, output string) error {
+	if err == nil {
+		return nil
+	}
+
+	var ee *exec.ExitError
+	if !errors.As(err, &ee) {
+		// The binary could not even start
+		return withExitCode(exitToolMissing, fmt.Errorf("%s could not start: %w", tool, err))
+	}
+
+	status := ee.ExitCode()
+	switch status {
+	case -1, 130: // Killed by a signal / Ctrl+C in the child
+		return withExitCode(exitAborted, &toolError{tool: tool, status: status, reason: "was interrupted", err: err})
+	case 127: // Command not found inside a wrapper script
+		return withExitCode(exitToolMissing, &toolError{tool: tool, status: status, reason: "could not find a command it needs", err: err})
+	}
+
+	for _, failure := range toolFailures {
+		if strings.Contains(output, failure.marker) {
+			return withExitCode(failure.code, &toolError{tool: tool, status: status, reason: failure.reason, err: err})
+		}
+	}
+	return withExitCode(exitToolFailed, &toolError{tool: tool, status: status, reason: "failed", err: err})
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.limit; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string { return string(t.buf) }
+
+// configFiles lists the base config followed by its overlays
+// Returns: []string - files in merge order
+func (a *App) configFiles() []string {
+	return append([]string{a.configPath}, a.configOverlays...)
+}
+
+// loadConfig loads and resolves the configuration once per invocation
+// Returns: (*config.ServerlessConfig, error) - the shared resolved configuration
+func (a *App) loadConfig() (*config.ServerlessConfig, error) {
+	if a.cfg != nil {
+		return a.cfg, nil
+	}
+
+	cfg, err := config.LoadFiles(a.configFiles(), "")
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+
+	if err := cfg.ApplyPreview(a.preview); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.ApplyExclusive(a.exclusively); err != nil {
+		return nil, err
+	}
+
+	cfg.RootPath = a.RootPath
+	a.cfg = cfg
+	return cfg, nil
+}
+
+// loadValidConfig loads the shared configuration and validates it
+// Returns: (*config.ServerlessConfig, error) - the configuration, or the validation error
+func (a *App) loadValidConfig() (*config.ServerlessConfig, error) {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	return cfg, nil
+}
+
+// binary resolves an external tool: flag value, else environment variable, else default name
+// Input: flag - value of the --*-bin flag, env - environment variable name, name - default binary
+// Returns: string - binary name or path to execute
+func (a *App) binary(flag, env, name string) string {
+	if flag != "" {
+		return flag
+	}
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return name
+}
+
+// checkCdkInstalled verifies the CDK CLI (--cdk-bin, $QRIOSLS_CDK_BIN or cdk in PATH) is available
+// Returns: (string, error) - path to CDK executable if found, error otherwise
+func (a *App) checkCdkInstalled() (string, error) {
+	bin := a.binary(a.cdkBin, cdkBinEnv, "cdk")
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return "", withExitCode(exitToolMissing, fmt.Errorf("CDK CLI '%s' not found: %w", bin, err))
+	}
+	return path, nil
+}
+
+// snapshotPath returns where the named template snapshot is stored
+// Input: name - snapshot name
+// Returns: string - path under .qriosls/snapshots/
+func snapshotPath(name string) string {
+	return filepath.Join(snapshotsDir, name+".template.json")
+}
+
+// validateSnapshotName checks a snapshot name is usable as a file name (empty is allowed)
+// Input: name - snapshot name from --save/--since
+// Returns: error if the name contains anything but letters, digits, '.', '_' or '-'
+func validateSnapshotName(name string) error {
+	if name != "" && !reSnapshotName.MatchString(name) {
+		return fmt.Errorf("snapshot name '%s' is invalid. Only letters, digits, '.', '_' and '-' allowed", name)
+	}
+	return nil
+}
+
+// runDeployWatch runs cdk deploy --watch, building the functions before each synth
+// Input: cfg - loaded configuration, ex - the prepared cdk deploy command
+// Returns: error if the watch settings cannot be written or cdk fails
+// Output: Streams cdk output until interrupted
+func (a *App) runDeployWatch(cfg *config.ServerlessConfig, ex *exec.Cmd) error {
+	log.Printf("⚠️ deploy --watch hotswaps changes into the real '%s' stack in AWS: use it for dev stages only, it can incur cost", cfg.StackName())
+
+	cleanup, err := a.writeWatchSettings(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ex.Args = append(ex.Args, "--watch")
+	ex.Env = append(ex.Env, buildOnSynthEnv+"=1")
+
+	// Ctrl+C stops cdk; we stay alive to remove the generated cdk.json
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	log.Printf("👀 Executing: %s", strings.Join(ex.Args, " "))
+	if err := runTool("cdk deploy --watch", ex); err != nil && len(interrupts) == 0 {
+		return err
+	}
+	return nil
+}
+
+// writeWatchSettings writes a cdk.json whose watch settings cover the function
+// code and config files, excluding build output that would retrigger the watch.
+// An existing cdk.json is left alone and its own watch settings are used.
+// Input: cfg - loaded configuration with the functions to watch
+// Returns: func() - removes the generated cdk.json, error if it cannot be written
+func (a *App) writeWatchSettings(cfg *config.ServerlessConfig) (func(), error) {
+	const cdkJSON = "cdk.json"
+	if _, err := os.Stat(cdkJSON); err == nil {
+		log.Printf("ℹ️ Using the watch settings of the existing %s", cdkJSON)
+		return func() {}, nil
+	}
+
+	include := append([]string(nil), a.configFiles()...)
+	exclude := []string{filepath.ToSlash(filepath.Clean(a.outputDir)), ".qriosls", "**/node_modules"}
+	active := cfg.ActiveFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		fn := active[funcName]
+		if fn.Artifact != "" {
+			include = append(include, fn.Artifact)
+			continue
+		}
+		code := filepath.ToSlash(filepath.Clean(fn.Code))
+		include = append(include, code, code+"/**")
+		// Go builds the bootstrap inside the code directory
+		exclude = append(exclude, code+"/"+config.ProvidedHandler)
+	}
+
+	settings := map[string]interface{}{
+		"watch": map[string][]string{"include": include, "exclude": exclude},
+	}
+	b, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(cdkJSON, b, 0644); err != nil {
+		return nil, fmt.Errorf("error writing %s for watch settings: %w", cdkJSON, err)
+	}
+	return func() { os.Remove(cdkJSON) }, nil
+}
+
+// saveSnapshot copies the synthesized stack template to the --save snapshot
+// Input: cfg - loaded configuration, used to locate the stack template
+// Returns: error if the template cannot be read or the snapshot written
+func (a *App) saveSnapshot(cfg *config.ServerlessConfig) error {
+	if a.saveAs == "" {
+		return nil
+	}
+
+	template, err := os.ReadFile(filepath.Join(a.outputPath(), cfg.StackName()+".template.json"))
+	if err != nil {
+		return fmt.Errorf("error reading synthesized template: %w", err)
+	}
+	if err := os.MkdirAll(snapshotsDir, 0755); err != nil {
+		return fmt.Errorf("error creating snapshots directory: %w", err)
+	}
+
+	path := snapshotPath(a.saveAs)
+	if err := os.WriteFile(path, template, 0644); err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+	log.Printf("📸 Saved template snapshot '%s' to %s", a.saveAs, path)
+	return nil
+}
+
+// prepareCdkEnvironment prepares environment variables for CDK execution
+// Input: cfg - loaded configuration used to resolve the region
+// Returns: []string - environment variables array with CDK_APP, CDK_OUTDIR and region configured
+func (a *App) prepareCdkEnvironment(cfg *config.ServerlessConfig) []string {
+	env := os.Environ()
+	appCommand := fmt.Sprintf("qriosls cdkapp --config %s", a.configPath)
+	for _, overlay := range a.configOverlays {
+		appCommand += fmt.Sprintf(" --config-overlay %s", overlay)
+	}
+	if a.preview != "" {
+		appCommand += fmt.Sprintf(" --preview %s", a.preview)
+	}
+	if len(a.exclusively) > 0 {
+		appCommand += fmt.Sprintf(" --exclusively %s", strings.Join(a.exclusively, ","))
+	}
+	env = append(env, "CDK_APP="+appCommand, "CDK_OUTDIR="+a.outputPath())
+
+	if region := a.resolveRegion(cfg); region != "" {
+		env = append(env, "AWS_REGION="+region, "AWS_DEFAULT_REGION="+region)
+	}
+	return env
+}
+
+// outputPath resolves --output-dir against the project root, so cdk, the cdkapp
+// and the local runner all write the same cloud assembly
+// Returns: string - absolute cloud assembly directory
+func (a *App) outputPath() string {
+	if filepath.IsAbs(a.outputDir) {
+		return filepath.Clean(a.outputDir)
+	}
+	return filepath.Join(a.RootPath, a.outputDir)
+}
+
+// resolveRegion determines the AWS region shared by every AWS-facing command
+// Input: cfg - loaded configuration, may be nil when no config is available
+// Returns: string - --region flag, else provider.region, else empty (AWS default chain)
+func (a *App) resolveRegion(cfg *config.ServerlessConfig) string {
+	if a.awsRegion != "" {
+		return a.awsRegion
+	}
+	if cfg != nil && cfg.Provider != nil {
+		return cfg.Provider.Region
+	}
+	return ""
+}
+
+// awsCliArgs builds the --profile/--region arguments for AWS CLI and SAM calls
+// Input: cfg - loaded configuration, may be nil
+// Returns: []string - arguments to append to the command line
+func (a *App) awsCliArgs(cfg *config.ServerlessConfig) []string {
+	var args []string
+	if a.awsProfile != "" {
+		args = append(args, "--profile", a.awsProfile)
+	}
+	if region := a.resolveRegion(cfg); region != "" {
+		args = append(args, "--region", region)
+	}
+	return args
+}
+
+// cdkProfileArgs builds the --profile argument for CDK CLI calls
+// (the CDK CLI takes the region from the environment, see prepareCdkEnvironment)
+// Returns: []string - arguments to append to the command line
+func (a *App) cdkProfileArgs() []string {
+	if a.awsProfile == "" {
+		return nil
+	}
+	return []string{"--profile", a.awsProfile}
+}
+
+// checkWritableDir creates dir if needed and verifies files can be written in it
+// Input: dir - target directory
+// Returns: error if dir is a file or not writable
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create %s: %w", dir, err)
+	}
+
+	f, err := os.CreateTemp(dir, ".qriosls-write-probe-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+// checkWritable verifies a file can be created or overwritten at path
+// Input: path - target file path
+// Returns: error if the parent directory is missing or the file cannot be opened for writing
+func checkWritable(path string) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("directory %s does not exist", filepath.Dir(path))
+	}
+
+	_, statErr := os.Stat(path)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", path, err)
+	}
+	f.Close()
+
+	// Don't leave an empty file behind if we created it just to probe
+	if os.IsNotExist(statErr) {
+		os.Remove(path)
+	}
+	return nil
+}
+
+// eventSource describes where a non-HTTP event comes from
+// Input: ev - the event definition
+// Returns: string - human-readable source for docs and listings
+func eventSource(ev config.LambdaEvent) string {
+	if ev.Schedule != "" {
+		return ev.Schedule
+	}
+	if arn := ev.QueueArn(); arn != "" {
+		return arn
+	}
+	if name := ev.SqsQueueName(); name != "" {
+		return name
+	}
+	if ev.Bucket != "" {
+		return "s3://" + ev.Bucket
+	}
+	return "-"
+}
+
+// eventSummaries describes each trigger of a function in one short string
+// Input: fn - the function definition
+// Returns: []string - "GET /path" for HTTP events, "type:source" otherwise
+func eventSummaries(fn config.LambdaFunc) []string {
+	summaries := make([]string, 0, len(fn.Events))
+	for _, ev := range fn.Events {
+		if strings.ToUpper(ev.Type) == "HTTP" {
+			summaries = append(summaries, strings.ToUpper(ev.Method)+" "+engine.RoutePath(ev))
+			continue
+		}
+		summaries = append(summaries, strings.ToLower(ev.Type)+":"+eventSource(ev))
+	}
+	return summaries
+}
+
+// planChanges lists function-level differences between two stages
+// Input: base - baseline stage functions, current - compared stage functions
+// Returns: []string - human-readable differences (names differing only by stage are ignored)
+func planChanges(base, current map[string]config.LambdaFunc) []string {
+	var changes []string
+
+	for _, name := range config.SortedFunctionNames(base) {
+		if _, ok := current[name]; !ok {
+			changes = append(changes, fmt.Sprintf("- %s (not deployed)", name))
+		}
+	}
+
+	for _, name := range config.SortedFunctionNames(current) {
+		cur := current[name]
+		old, ok := base[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("+ %s (only in this stage)", name))
+			continue
+		}
+
+		fields := []struct {
+			field    string
+			old, new string
+		}{
+			{"runtime", old.Runtime, cur.Runtime},
+			{"handler", old.Handler, cur.Handler},
+			{"code", old.Code, cur.Code},
+			{"artifact", old.Artifact, cur.Artifact},
+			{"memorySize", fmt.Sprint(old.MemorySize), fmt.Sprint(cur.MemorySize)},
+			{"timeout", fmt.Sprint(old.Timeout), fmt.Sprint(cur.Timeout)},
+			{"layers", strings.Join(old.Layers, ", "), strings.Join(cur.Layers, ", ")},
+			{"architecture", old.Architecture, cur.Architecture},
+			{"events", strings.Join(eventSummaries(old), ", "), strings.Join(eventSummaries(cur), ", ")},
+		}
+		for _, f := range fields {
+			if f.old != f.new {
+				changes = append(changes, fmt.Sprintf("~ %s.%s: %s → %s", name, f.field, f.old, f.new))
+			}
+		}
+	}
+
+	return changes
+}
+
+// checkNode verifies if Node.js is installed and available
+// Returns: error if Node.js is not found in PATH
+func (a *App) checkNode() error {
+	_, err := exec.LookPath("node")
+	return err
+}
+
+// checkCdk verifies if AWS CDK CLI is installed and available
+// Returns: error if the configured CDK binary is not found
+func (a *App) checkCdk() error {
+	_, err := a.checkCdkInstalled()
+	return err
+}
+
+// checkSam verifies if the SAM CLI used by local mode is installed and available
+// Returns: error if the configured SAM binary is not found
+func (a *App) checkSam() error {
+	_, err := a.checkSamInstalled()
+	return err
+}
+
+// checkSamInstalled verifies the SAM CLI (--sam-bin, $QRIOSLS_SAM_BIN or sam in PATH) is available
+// Returns: (string, error) - path to the SAM executable, or an error with install guidance
+func (a *App) checkSamInstalled() (string, error) {
+	bin := a.binary(a.samBin, samBinEnv, "sam")
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return "", withExitCode(exitToolMissing, fmt.Errorf("SAM CLI '%s' not found: local mode needs it to serve the API. "+
+			"Install it (https://docs.aws.amazon.com/serverless-application-model/latest/developerguide/install-sam-cli.html) "+
+			"or point --sam-bin/$%s at it", bin, samBinEnv))
+	}
+	return path, nil
+}
+
+// checkDocker verifies Docker is installed, since SAM runs each function in a container
+// Returns: error with install guidance if docker is not found in PATH
+func (a *App) checkDocker() error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return withExitCode(exitToolMissing, fmt.Errorf("docker not found: SAM CLI runs functions in containers. "+
+			"Install Docker (https://docs.docker.com/get-docker/) and make sure the daemon is running"))
+	}
+	return nil
 }
 
 // checkGo verifies if Go programming language is installed
@@ -476,7 +2067,8 @@ func (a *App) checkGo() error {
 // Returns: error if AWS credentials are invalid or AWS CLI not installed
 func (a *App) checkAwsCredentials() error {
 	var out bytes.Buffer
-	cmd := exec.Command("aws", "sts", "get-caller-identity")
+	cmdArgs := append([]string{"sts", "get-caller-identity"}, a.awsCliArgs(nil)...)
+	cmd := exec.Command("aws", cmdArgs...)
 	cmd.Stdout = &out
 
 	if err := cmd.Run(); err != nil {
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestHelperProcess is the fake cdk/sam: it writes its stderr argument and exits with
+// the given status (-1 = killed by a signal). It is a no-op when run as a normal test.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("QRIOSLS_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	status, _ := strconv.Atoi(args[1])
+	fmt.Fprint(os.Stderr, args[2])
+	if status == -1 {
+		syscall.Kill(os.Getpid(), syscall.SIGKILL)
+	}
+	os.Exit(status)
+}
+
+// fakeTool prepares a command that runs TestHelperProcess as the tool
+func fakeTool(status int, stderr string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$", "--", strconv.Itoa(status), stderr)
+	cmd.Env = append(os.Environ(), "QRIOSLS_HELPER_PROCESS=1")
+	return cmd
+}
+
+// toolCase is a fake tool run and how runTool should classify it (wantCode 0 = success)
+type toolCase struct {
+	name       string
+	cmd        *exec.Cmd
+	wantCode   int
+	wantReason string
+}
+
+func TestRunToolClassifiesFailures(t *testing.T) {
+	tests := []toolCase{
+		{name: "success", cmd: fakeTool(0, "")},
+		{name: "killed by a signal", cmd: fakeTool(-1, ""), wantCode: exitAborted, wantReason: "was interrupted"},
+		{name: "ctrl+c in the child", cmd: fakeTool(130, ""), wantCode: exitAborted, wantReason: "was interrupted"},
+		{name: "command not found", cmd: fakeTool(127, "sh: docker: not found"), wantCode: exitToolMissing, wantReason: "could not find a command it needs"},
+		{name: "binary missing", cmd: exec.Command("/nonexistent/qriosls-tool"), wantCode: exitToolMissing, wantReason: "could not start"},
+		{name: "other failure", cmd: fakeTool(1, "Error: something broke"), wantCode: exitToolFailed, wantReason: "failed (exit status 1)"},
+	}
+	for _, failure := range toolFailures {
+		tests = append(tests, toolCase{
+			name:       failure.marker,
+			cmd:        fakeTool(1, "stack svc-dev: "+failure.marker+"\n"),
+			wantCode:   failure.code,
+			wantReason: failure.reason,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runTool("cdk deploy", tt.cmd)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("runTool error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("runTool returned no error")
+			}
+			if code := exitCode(err); code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d (%v)", code, tt.wantCode, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantReason) {
+				t.Errorf("error %q does not mention %q", err, tt.wantReason)
+			}
+			if !strings.HasPrefix(err.Error(), "cdk deploy ") {
+				t.Errorf("error %q does not name the tool", err)
+			}
+		})
+	}
+}
+
+// startTool keeps classifying after the call returns, as sam local start-api needs
+func TestStartToolClassifiesOnWait(t *testing.T) {
+	wait, err := startTool("sam local start-api", fakeTool(127, ""))
+	if err != nil {
+		t.Fatalf("startTool: %v", err)
+	}
+	if err := wait(); exitCode(err) != exitToolMissing {
+		t.Errorf("wait error = %v, want exit code %d", err, exitToolMissing)
+	}
+}
+
+func TestDeployArgsOutputsFile(t *testing.T) {
+	dir := t.TempDir()
+	readOnly := filepath.Join(dir, "ro")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		outputsFile string
+		wantArgs    []string
+		wantErr     string
+		skipAsRoot  bool // root writes through 0555
+	}{
+		{name: "no outputs file", wantArgs: []string{"deploy", "--output", "cdk.out"}},
+		{name: "new file", outputsFile: filepath.Join(dir, "outputs.json"),
+			wantArgs: []string{"deploy", "--output", "cdk.out", "--outputs-file", filepath.Join(dir, "outputs.json")}},
+		{name: "missing directory", outputsFile: filepath.Join(dir, "missing", "outputs.json"),
+			wantErr: "invalid --outputs-file: directory " + filepath.Join(dir, "missing") + " does not exist"},
+		{name: "directory", outputsFile: dir, wantErr: "is a directory"},
+		{name: "read-only directory", outputsFile: filepath.Join(readOnly, "outputs.json"), wantErr: "is not writable", skipAsRoot: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.skipAsRoot && os.Geteuid() == 0 {
+				t.Skip("root can write to a read-only directory")
+			}
+			a := &App{outputDir: "cdk.out", outputsFile: tt.outputsFile}
+			args, err := a.deployArgs()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("deployArgs error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("deployArgs = %v, want %v", args, tt.wantArgs)
+			}
+			if tt.outputsFile != "" {
+				if _, err := os.Stat(tt.outputsFile); !os.IsNotExist(err) {
+					t.Errorf("the writability probe left %s behind", tt.outputsFile)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputDirResolvesAgainstRoot(t *testing.T) {
+	root := t.TempDir()
+	abs := filepath.Join(t.TempDir(), "assembly")
+
+	tests := []struct {
+		outputDir string
+		want      string
+	}{
+		{outputDir: "cdk.out", want: filepath.Join(root, "cdk.out")},
+		{outputDir: "build/../cdk.dev", want: filepath.Join(root, "cdk.dev")},
+		{outputDir: abs, want: abs},
+	}
+	for _, tt := range tests {
+		a := &App{RootPath: root, outputDir: tt.outputDir, configPath: "qriosls.yml"}
+		if got := a.outputPath(); got != tt.want {
+			t.Errorf("outputPath(%q) = %q, want %q", tt.outputDir, got, tt.want)
+		}
+		if env := a.prepareCdkEnvironment(nil); !strings.Contains(strings.Join(env, "\n")+"\n", "\nCDK_OUTDIR="+tt.want+"\n") {
+			t.Errorf("prepareCdkEnvironment(%q) does not set CDK_OUTDIR=%s", tt.outputDir, tt.want)
+		}
+	}
+}
+
+// fakeBin writes an executable named name into dir
+func fakeBin(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckSamInstalled(t *testing.T) {
+	inPath := t.TempDir()
+	elsewhere := t.TempDir()
+	sam := fakeBin(t, inPath, "sam")
+	custom := fakeBin(t, elsewhere, "sam-custom")
+
+	tests := []struct {
+		name     string
+		flag     string
+		env      string
+		path     string
+		wantPath string
+	}{
+		{name: "sam in PATH", path: inPath, wantPath: sam},
+		{name: "--sam-bin", flag: custom, path: inPath, wantPath: custom},
+		{name: "env override", env: custom, wantPath: custom},
+		{name: "not installed", path: elsewhere},
+		{name: "--sam-bin missing", flag: filepath.Join(elsewhere, "missing"), path: inPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", tt.path)
+			t.Setenv(samBinEnv, tt.env)
+
+			got, err := (&App{samBin: tt.flag}).checkSamInstalled()
+			if tt.wantPath == "" {
+				if err == nil {
+					t.Fatalf("checkSamInstalled = %s, want an error", got)
+				}
+				if exitCode(err) != exitToolMissing || !strings.Contains(err.Error(), "install-sam-cli") {
+					t.Errorf("error %v should carry exit code %d and install guidance", err, exitToolMissing)
+				}
+				return
+			}
+			if err != nil || got != tt.wantPath {
+				t.Fatalf("checkSamInstalled = %s, %v, want %s", got, err, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestCheckDocker(t *testing.T) {
+	withDocker := t.TempDir()
+	fakeBin(t, withDocker, "docker")
+
+	t.Setenv("PATH", withDocker)
+	if err := (&App{}).checkDocker(); err != nil {
+		t.Fatalf("checkDocker with docker in PATH = %v", err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	err := (&App{}).checkDocker()
+	if err == nil || exitCode(err) != exitToolMissing || !strings.Contains(err.Error(), "get-docker") {
+		t.Fatalf("checkDocker without docker = %v, want install guidance with exit code %d", err, exitToolMissing)
+	}
+}
+
+// local fails on a missing SAM CLI or Docker before it loads the config or builds anything
+func TestRunLocalChecksToolsFirst(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv(samBinEnv, "")
+
+	a := &App{configPath: filepath.Join(t.TempDir(), "missing.yml")}
+	err := a.runLocal(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "SAM CLI 'sam' not found") {
+		t.Fatalf("runLocal = %v, want the SAM CLI error before loading the config", err)
+	}
+
+	sam := t.TempDir()
+	fakeBin(t, sam, "sam")
+	t.Setenv("PATH", sam)
+	err = a.runLocal(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "docker not found") {
+		t.Fatalf("runLocal = %v, want the docker error before loading the config", err)
+	}
+}
+
+func TestPlanBaselineStage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qrioso-sls.yml")
+	doc := "service: svc\nstage: dev\nstages:\n  prod: {}\n  qa: {}\nfunctions: {}\n"
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		from     string
+		explicit bool
+		want     string
+		wantErr  string
+	}{
+		{name: "default", from: defaultStage, want: "dev"},
+		{name: "declared stage", from: "qa", explicit: true, want: "qa"},
+		{name: "base stage", from: "dev", explicit: true, want: "dev"},
+		{name: "undeclared stage", from: "stg", explicit: true,
+			wantErr: "stage 'stg' in --from is not declared in the config (stages: dev, prod, qa)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &App{configPath: path, planFrom: tt.from}
+			got, err := a.planBaselineStage(tt.explicit)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("planBaselineStage = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("planBaselineStage = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -2,5 +2,5 @@ package assets
 
 import "embed"
 
-//go:embed templates/*.tmpl.yml
+//go:embed templates/*.tmpl.yml templates/*.tmpl.md
 var Templates embed.FS
@@ -3,26 +3,103 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/qrioso-software/qriososls/internal/util"
 	"gopkg.in/yaml.v3"
 )
 
 type ApiConfig struct {
-	Id             string `yaml:"id"`
-	RootResourceId string `yaml:"rootResourceId"`
-	Name           string `yaml:"name"`
+	Id             string                `yaml:"id,omitempty"`
+	RootResourceId string                `yaml:"rootResourceId,omitempty"`
+	Name           string                `yaml:"name,omitempty"`
+	ResourcePolicy *ResourcePolicyConfig `yaml:"resourcePolicy,omitempty"`
+	Cors           *CorsConfig           `yaml:"cors,omitempty"` // Preflight por defecto de todos los recursos
+
+	GatewayResponses  map[string]GatewayResponseConfig `yaml:"gatewayResponses,omitempty"`  // Clave: tipo (DEFAULT_4XX, THROTTLED...)
+	RequestValidation string                           `yaml:"requestValidation,omitempty"` // Validator por defecto de cada método: none|params|all
+
+	Domain *DomainConfig `yaml:"domain,omitempty"`
+}
+
+// Modos de validación de requests en API Gateway
+const (
+	RequestValidationNone   = "none"
+	RequestValidationParams = "params"
+	RequestValidationAll    = "all"
+)
+
+func validRequestValidation(mode string) bool {
+	switch mode {
+	case "", RequestValidationNone, RequestValidationParams, RequestValidationAll:
+		return true
+	}
+	return false
+}
+
+// Respuesta de error propia de API Gateway para un tipo de respuesta
+type GatewayResponseConfig struct {
+	StatusCode string            `yaml:"statusCode,omitempty"` // Vacío = el código por defecto del tipo
+	Headers    map[string]string `yaml:"headers,omitempty"`    // Valores estáticos
+	Templates  map[string]string `yaml:"templates,omitempty"`  // Content-Type -> template
+}
+
+// Restricciones de acceso al API por IP/CIDR o VPC endpoint
+type ResourcePolicyConfig struct {
+	Allow *PolicySources `yaml:"allow,omitempty"`
+	Deny  *PolicySources `yaml:"deny,omitempty"`
+}
+
+type PolicySources struct {
+	SourceIps    []string `yaml:"sourceIps,omitempty"`
+	VpcEndpoints []string `yaml:"vpcEndpoints,omitempty"`
+}
+
+// Valores por defecto compartidos por todas las funciones
+type ProviderConfig struct {
+	Runtime   string     `yaml:"runtime,omitempty"`
+	Region    string     `yaml:"region,omitempty"`
+	Vpc       *VpcConfig `yaml:"vpc,omitempty"`       // Heredada por las funciones sin vpc propia
+	KmsKeyArn string     `yaml:"kmsKeyArn,omitempty"` // Heredada por las funciones sin kmsKeyArn propio
+
+	MemorySize int `yaml:"memorySize,omitempty"` // MB, para las funciones sin memorySize propio
+	Timeout    int `yaml:"timeout,omitempty"`    // Segundos, para las funciones sin timeout propio
+
+	Environment map[string]string `yaml:"environment,omitempty"` // Comunes a todas las funciones; la función prevalece por clave
+}
+
+// Subnets y security groups existentes en los que corre la función
+type VpcConfig struct {
+	SubnetIds        []string `yaml:"subnetIds"`
+	SecurityGroupIds []string `yaml:"securityGroupIds"`
 }
 
 type ServerlessConfig struct {
-	Service   string                `yaml:"service"`
-	Stage     string                `yaml:"stage"`
-	Api       *ApiConfig            `yaml:"api"`
-	Functions map[string]LambdaFunc `yaml:"functions"`
-	RootPath  string                `yaml:"-"`
+	Version    int                   `yaml:"version,omitempty"` // Versión del esquema (0 = 1, ver Migrate)
+	Service    string                `yaml:"service"`
+	Stage      string                `yaml:"stage"`
+	Provider   *ProviderConfig       `yaml:"provider,omitempty"`
+	Api        *ApiConfig            `yaml:"api,omitempty"`
+	Functions  map[string]LambdaFunc `yaml:"functions"`
+	RootPath   string                `yaml:"-"`
+	Preview    string                `yaml:"-"` // Sufijo de los despliegues de preview (ver ApplyPreview)
+	SourceHash string                `yaml:"-"` // sha256 de los archivos de config de origen
+
+	Description string `yaml:"description,omitempty"` // Descripción del stack en la consola de CloudFormation
 }
 
+// Límite de CloudFormation para la descripción de un stack
+const maxStackDescriptionLength = 1024
+
+// Límite de Lambda de layers por función
+const maxLayers = 5
+
 type LambdaFunc struct {
 	FunctionName string        `yaml:"functionName"`
 	Runtime      string        `yaml:"runtime"`
@@ -30,30 +107,379 @@ type LambdaFunc struct {
 	Code         string        `yaml:"code"`
 	MemorySize   int           `yaml:"memorySize"`
 	Timeout      int           `yaml:"timeout"`
-	Events       []LambdaEvent `yaml:"events"`
+	Events       []LambdaEvent `yaml:"events,omitempty"`
+	SkipInstall  bool          `yaml:"skipInstall,omitempty"`
+	Stages       []string      `yaml:"stages,omitempty"`
+	Enabled      *bool         `yaml:"enabled,omitempty"`
+	Role         string        `yaml:"role,omitempty"`
+	RoleArn      string        `yaml:"roleArn,omitempty"`    // Alias de role
+	ModuleRoot   string        `yaml:"moduleRoot,omitempty"` // Go: raíz del módulo (con go.mod) desde la que se compila
+	Vpc          *VpcConfig    `yaml:"vpc,omitempty"`        // Reemplaza a provider.vpc
+	KmsKeyArn    string        `yaml:"kmsKeyArn,omitempty"`  // CMK con la que se cifran las variables de entorno
+
+	RuntimeManagement *RuntimeManagementConfig `yaml:"runtimeManagement,omitempty"`
+	Environment       map[string]string        `yaml:"environment,omitempty"` // Valor escalar o map por stage (resuelto en Load)
+
+	Artifact     string              `yaml:"artifact,omitempty"`     // Zip ya compilado que se despliega tal cual (reemplaza a code)
+	Destinations *DestinationsConfig `yaml:"destinations,omitempty"` // Destinos de las invocaciones asíncronas
+
+	IamRoleStatements []IamRoleStatement `yaml:"iamRoleStatements,omitempty"` // Permisos extra del rol creado por CDK
+	Layers            []string           `yaml:"layers,omitempty"`            // ARNs de versiones de layers (máx. 5)
+
+	Architecture string `yaml:"architecture,omitempty"` // x86_64 (por defecto) o arm64 (Graviton)
+}
+
+// AssetPath devuelve lo que se empaqueta como código de la función:
+// el zip de artifact o, si no hay, el directorio code
+func (f LambdaFunc) AssetPath() string {
+	if f.Artifact != "" {
+		return f.Artifact
+	}
+	return f.Code
+}
+
+// Directorio, relativo a la raíz del proyecto, donde se construyen las
+// funciones que no se empaquetan desde code (ver UsesBuildDir)
+const BuildDir = ".qriosls/build"
+
+// UsesBuildDir indica si la función se empaqueta desde su build dir: Python
+// con requirements.txt en code, que instala las dependencias junto a una copia
+// del código para no ensuciar el código fuente
+func (c *ServerlessConfig) UsesBuildDir(funcName string) bool {
+	f, ok := c.Functions[funcName]
+	if !ok || f.Artifact != "" || !strings.HasPrefix(CanonicalRuntime(f.Runtime), "python") {
+		return false
+	}
+	code := util.ResolveVars(f.Code, c.Stage)
+	if !filepath.IsAbs(code) {
+		code = filepath.Join(c.RootPath, code)
+	}
+	_, err := os.Stat(filepath.Join(code, "requirements.txt"))
+	return err == nil
+}
+
+// FunctionAssetPath devuelve la ruta, relativa a la raíz como code, que se
+// empaqueta como asset de la función (build dir o AssetPath, ya resuelta para
+// el stage): la misma en el engine y en el runner local
+func (c *ServerlessConfig) FunctionAssetPath(funcName string) string {
+	if c.UsesBuildDir(funcName) {
+		return filepath.ToSlash(filepath.Join(filepath.FromSlash(BuildDir), funcName))
+	}
+	return util.ResolveVars(c.Functions[funcName].AssetPath(), c.Stage)
+}
+
+// ExecutionRole devuelve el ARN del rol existente (role o roleArn), o "" si
+// CDK debe crear el rol de ejecución
+func (f LambdaFunc) ExecutionRole() string {
+	if f.Role != "" {
+		return f.Role
+	}
+	return f.RoleArn
+}
+
+// Arquitecturas de Lambda
+const (
+	ArchitectureX86_64 = "x86_64"
+	ArchitectureArm64  = "arm64"
+)
+
+// GoArch devuelve el GOARCH con el que se compila la función
+func (f LambdaFunc) GoArch() string {
+	if f.Architecture == ArchitectureArm64 {
+		return "arm64"
+	}
+	return "amd64"
+}
+
+// Modos de actualización del runtime de Lambda
+const (
+	RuntimeManagementAuto           = "Auto"
+	RuntimeManagementFunctionUpdate = "FunctionUpdate"
+	RuntimeManagementManual         = "Manual"
+)
+
+type RuntimeManagementConfig struct {
+	Mode              string `yaml:"mode"`
+	RuntimeVersionArn string `yaml:"runtimeVersionArn,omitempty"`
 }
 
 type LambdaEvent struct {
-	Type     string `yaml:"type"`
-	Resource string `yaml:"resource"`
-	Path     string `yaml:"path"`
-	Method   string `yaml:"method"`
+	Type     string      `yaml:"type"`
+	Resource string      `yaml:"resource,omitempty"`
+	Path     string      `yaml:"path,omitempty"`
+	Method   string      `yaml:"method,omitempty"`
+	Cors     *CorsConfig `yaml:"cors,omitempty"`     // Preflight solo para el recurso de este evento
+	Schedule string      `yaml:"schedule,omitempty"` // schedule: rate(...) o cron(...)
+
+	RequestValidation string `yaml:"requestValidation,omitempty"` // Reemplaza a api.requestValidation en este método
+
+	RequestSchema interface{}        `yaml:"requestSchema,omitempty"` // JSON schema del body: ruta a un archivo o objeto en línea
+	Request       *HttpRequestConfig `yaml:"request,omitempty"`       // Query strings/headers requeridos y schema del body
+
+	// sqs: la cola por ARN (o resource) o por nombre en la cuenta/región del stack
+	Arn                   string `yaml:"arn,omitempty"`
+	QueueName             string `yaml:"queueName,omitempty"`
+	Queue                 string `yaml:"queue,omitempty"`                 // Atajo: ARN o nombre de la cola
+	BatchSize             int    `yaml:"batchSize,omitempty"`             // 1-10000 (más de 10 requiere maximumBatchingWindow)
+	MaximumBatchingWindow int    `yaml:"maximumBatchingWindow,omitempty"` // Segundos, 0-300
+
+	Enabled *bool `yaml:"enabled,omitempty"` // schedule: false crea la regla deshabilitada (nil = habilitada)
+
+	// s3: notificaciones de un bucket creado por el stack (con --preview lleva el sufijo) o, con existing, de uno ya existente
+	Bucket   string `yaml:"bucket,omitempty"`
+	Event    string `yaml:"event,omitempty"` // s3:ObjectCreated:*, s3:ObjectRemoved:Delete...
+	Prefix   string `yaml:"prefix,omitempty"`
+	Suffix   string `yaml:"suffix,omitempty"`
+	Existing bool   `yaml:"existing,omitempty"`
+}
+
+// QueueArn devuelve el ARN de la cola de un evento sqs (arn, queue con un ARN o,
+// por compatibilidad, resource); vacío si la cola se indica por nombre
+func (e LambdaEvent) QueueArn() string {
+	switch {
+	case e.Arn != "":
+		return e.Arn
+	case strings.HasPrefix(e.Queue, "arn:"):
+		return e.Queue
+	}
+	return e.Resource
+}
+
+// SqsQueueName devuelve el nombre de la cola de un evento sqs (queueName o queue
+// sin ARN); vacío si la cola se indica por ARN
+func (e LambdaEvent) SqsQueueName() string {
+	if e.QueueName == "" && !strings.HasPrefix(e.Queue, "arn:") {
+		return e.Queue
+	}
+	return e.QueueName
+}
+
+// Opciones de CORS (preflight OPTIONS) de un recurso
+type CorsConfig struct {
+	AllowOrigins     []string `yaml:"allowOrigins"`
+	AllowMethods     []string `yaml:"allowMethods,omitempty"` // Vacío = todos
+	AllowHeaders     []string `yaml:"allowHeaders,omitempty"`
+	AllowCredentials bool     `yaml:"allowCredentials,omitempty"`
 }
 
 func Load(path string) (*ServerlessConfig, error) {
-	b, err := os.ReadFile(path)
+	return LoadStage(path, "")
+}
+
+// LoadStage carga la configuración para un stage concreto (vacío = el del archivo),
+// aplicando el bloque stages.<stage>, los defaults de provider y la interpolación
+func LoadStage(path, stage string) (*ServerlessConfig, error) {
+	return LoadFiles([]string{path}, stage)
+}
+
+// LoadFiles carga varios archivos mezclados en orden (base primero, overlays
+// después) y luego aplica el stage igual que LoadStage
+func LoadFiles(paths []string, stage string) (*ServerlessConfig, error) {
+	doc, err := readDocuments(paths)
 	if err != nil {
-		return nil, fmt.Errorf("error reading config file: %w", err)
+		return nil, err
+	}
+
+	if doc, err = applyStageOverrides(doc, stage); err != nil {
+		return nil, err
+	}
+
+	if err := normalizeUnits(doc); err != nil {
+		return nil, err
 	}
 
+	if err := resolveStageEnvironment(doc); err != nil {
+		return nil, err
+	}
+
+	b, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error merging config: %w", err)
+	}
+
+	// Los tipos ya se validan aquí: p. ej. enabled: "yes" falla al no ser booleano
 	var c ServerlessConfig
 	if err := yaml.Unmarshal(b, &c); err != nil {
 		return nil, fmt.Errorf("error parsing YAML: %w", err)
 	}
 
+	if c.Version > CurrentVersion {
+		return nil, fmt.Errorf("config version %d is newer than the supported version %d (upgrade qriosls)", c.Version, CurrentVersion)
+	}
+
+	if c.SourceHash, err = sourceHash(paths); err != nil {
+		return nil, err
+	}
+
+	c.ApplyDefaults()
+
+	if err := c.Resolve(); err != nil {
+		return nil, fmt.Errorf("error resolving variables: %w", err)
+	}
+
 	return &c, nil
 }
 
+func readFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	return b, nil
+}
+
+// ActiveFunctions devuelve las funciones habilitadas que se despliegan en el stage actual
+func (c *ServerlessConfig) ActiveFunctions() map[string]LambdaFunc {
+	active := make(map[string]LambdaFunc, len(c.Functions))
+	for funcName, function := range c.Functions {
+		if function.IsEnabled() && function.InStage(c.Stage) {
+			active[funcName] = function
+		}
+	}
+	return active
+}
+
+// IsEnabled indica si la función participa en synth/build (por defecto sí)
+func (f *LambdaFunc) IsEnabled() bool {
+	return f.Enabled == nil || *f.Enabled
+}
+
+// InStage indica si la función existe en el stage dado (sin stages = todos)
+func (f *LambdaFunc) InStage(stage string) bool {
+	if len(f.Stages) == 0 {
+		return true
+	}
+	for _, s := range f.Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyDefaults completa cada función con los valores de provider que omite.
+// Los valores definidos en la función siempre tienen prioridad. Los runtimes
+// provided sin handler usan ProvidedHandler.
+func (c *ServerlessConfig) ApplyDefaults() {
+	for funcName, function := range c.Functions {
+		if function.Runtime == "" && c.Provider != nil {
+			function.Runtime = c.Provider.Runtime
+		}
+		if function.KmsKeyArn == "" && c.Provider != nil {
+			function.KmsKeyArn = c.Provider.KmsKeyArn
+		}
+		if function.MemorySize == 0 && c.Provider != nil {
+			function.MemorySize = c.Provider.MemorySize
+		}
+		if function.Timeout == 0 && c.Provider != nil {
+			function.Timeout = c.Provider.Timeout
+		}
+		// Map propio por función: Resolve escribe en él
+		if c.Provider != nil && len(c.Provider.Environment) > 0 {
+			env := make(map[string]string, len(c.Provider.Environment)+len(function.Environment))
+			for name, value := range c.Provider.Environment {
+				env[name] = value
+			}
+			for name, value := range function.Environment {
+				env[name] = value
+			}
+			function.Environment = env
+		}
+		// Copia propia: Resolve interpola los ids de cada función por separado
+		if function.Vpc == nil && c.Provider != nil && c.Provider.Vpc != nil {
+			function.Vpc = &VpcConfig{
+				SubnetIds:        append([]string(nil), c.Provider.Vpc.SubnetIds...),
+				SecurityGroupIds: append([]string(nil), c.Provider.Vpc.SecurityGroupIds...),
+			}
+		}
+		if function.Handler == "" && IsProvidedRuntime(function.Runtime) {
+			function.Handler = ProvidedHandler
+		}
+		c.Functions[funcName] = function
+	}
+}
+
+// Resolve aplica la interpolación de variables (${stage}, ${self:...}, ${env:...})
+// a los campos de texto de la configuración. Es el mismo camino que usa synth.
+func (c *ServerlessConfig) Resolve() error {
+	self := map[string]string{
+		"service": c.Service,
+		"stage":   c.Stage,
+	}
+
+	resolve := func(field *string) error {
+		v, err := util.Interpolate(*field, self)
+		if err != nil {
+			return err
+		}
+		*field = v
+		return nil
+	}
+
+	if err := resolve(&c.Description); err != nil {
+		return fmt.Errorf("description: %w", err)
+	}
+
+	if c.Api != nil {
+		fields := []*string{&c.Api.Id, &c.Api.RootResourceId, &c.Api.Name}
+		if c.Api.Domain != nil {
+			fields = append(fields, &c.Api.Domain.DomainName, &c.Api.Domain.CertificateArn, &c.Api.Domain.BasePath)
+		}
+		for _, field := range fields {
+			if err := resolve(field); err != nil {
+				return fmt.Errorf("api: %w", err)
+			}
+		}
+	}
+
+	for funcName, function := range c.Functions {
+		fields := []*string{&function.FunctionName, &function.Runtime, &function.Handler, &function.Code,
+			&function.Artifact, &function.Role, &function.RoleArn, &function.ModuleRoot, &function.KmsKeyArn}
+		for i := range function.Events {
+			fields = append(fields, &function.Events[i].Resource, &function.Events[i].Path,
+				&function.Events[i].Arn, &function.Events[i].QueueName, &function.Events[i].Queue,
+				&function.Events[i].Bucket, &function.Events[i].Prefix, &function.Events[i].Suffix)
+		}
+		if function.Destinations != nil {
+			fields = append(fields, &function.Destinations.OnSuccess, &function.Destinations.OnFailure)
+		}
+		for i := range function.Layers {
+			fields = append(fields, &function.Layers[i])
+		}
+		for i := range function.IamRoleStatements {
+			for j := range function.IamRoleStatements[i].Resource {
+				fields = append(fields, &function.IamRoleStatements[i].Resource[j])
+			}
+		}
+		if function.Vpc != nil {
+			for i := range function.Vpc.SubnetIds {
+				fields = append(fields, &function.Vpc.SubnetIds[i])
+			}
+			for i := range function.Vpc.SecurityGroupIds {
+				fields = append(fields, &function.Vpc.SecurityGroupIds[i])
+			}
+		}
+
+		for _, field := range fields {
+			if err := resolve(field); err != nil {
+				return fmt.Errorf("function '%s': %w", funcName, err)
+			}
+		}
+		for name, value := range function.Environment {
+			if err := resolve(&value); err != nil {
+				return fmt.Errorf("function '%s': environment.%s: %w", funcName, name, err)
+			}
+			function.Environment[name] = value
+		}
+		c.Functions[funcName] = function
+	}
+
+	return nil
+}
+
+// Validate es el único validador de la configuración ya resuelta: campos
+// requeridos del servicio, límites de provider, cada función en orden estable
+// y las reglas entre funciones (buckets, rutas)
 func (c *ServerlessConfig) Validate() error {
 	if c.Service == "" {
 		return fmt.Errorf("field 'service' is required")
@@ -67,16 +493,166 @@ func (c *ServerlessConfig) Validate() error {
 		return fmt.Errorf("field 'stage' is required")
 	}
 
+	if c.Api != nil {
+		if err := c.Api.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Provider != nil && c.Provider.Vpc != nil {
+		if err := c.Provider.Vpc.Validate(); err != nil {
+			return fmt.Errorf("provider.vpc: %w", err)
+		}
+	}
+
+	if c.Provider != nil {
+		if err := validateEnvironment(c.Provider.Environment); err != nil {
+			return fmt.Errorf("provider.environment: %w", err)
+		}
+	}
+
+	if c.Provider != nil && c.Provider.KmsKeyArn != "" && !reKmsKeyArn.MatchString(c.Provider.KmsKeyArn) {
+		return fmt.Errorf("provider.kmsKeyArn '%s' is not a valid KMS key ARN", c.Provider.KmsKeyArn)
+	}
+
+	if c.Provider != nil && c.Provider.MemorySize != 0 && (c.Provider.MemorySize < 128 || c.Provider.MemorySize > 10240) {
+		return fmt.Errorf("provider.memorySize must be between 128 and 10240")
+	}
+
+	if c.Provider != nil && c.Provider.Timeout != 0 && (c.Provider.Timeout < 1 || c.Provider.Timeout > 900) {
+		return fmt.Errorf("provider.timeout must be between 1 and 900 seconds")
+	}
+
+	if name := c.StackName(); len(name) > maxStackNameLength {
+		return fmt.Errorf("stack name '%s' exceeds %d characters", name, maxStackNameLength)
+	}
+
+	if len(c.Description) > maxStackDescriptionLength {
+		return fmt.Errorf("description exceeds %d characters", maxStackDescriptionLength)
+	}
+
 	if len(c.Functions) == 0 {
 		return fmt.Errorf("at least one function must be defined")
 	}
 
-	for funcName, function := range c.Functions {
+	// Orden estable: con varios errores siempre se reporta el mismo
+	for _, funcName := range SortedFunctionNames(c.Functions) {
+		function := c.Functions[funcName]
 		if err := function.Validate(funcName); err != nil {
 			return err
 		}
 	}
 
+	if err := c.validateBuckets(); err != nil {
+		return err
+	}
+
+	return c.validateRoutes()
+}
+
+// Un bucket lo crea el stack o ya existe: todos sus eventos s3 deben coincidir en existing
+func (c *ServerlessConfig) validateBuckets() error {
+	active := c.ActiveFunctions()
+	existing := make(map[string]bool)
+	for _, funcName := range SortedFunctionNames(active) {
+		for _, e := range active[funcName].Events {
+			if strings.ToLower(e.Type) != "s3" {
+				continue
+			}
+			if prev, ok := existing[e.Bucket]; ok && prev != e.Existing {
+				return fmt.Errorf("bucket '%s' is marked existing in some s3 events and not in others (function '%s')", e.Bucket, funcName)
+			}
+			existing[e.Bucket] = e.Existing
+		}
+	}
+	return nil
+}
+
+// Un método por ruta: dos funciones con el mismo METHOD /path (p. ej. un
+// OPTIONS propio para preflight) chocarían en API Gateway
+func (c *ServerlessConfig) validateRoutes() error {
+	active := c.ActiveFunctions()
+	owners := make(map[string]string)
+	cors := make(map[string]*CorsConfig)
+
+	for _, funcName := range SortedFunctionNames(active) {
+		for _, e := range active[funcName].Events {
+			if strings.ToLower(e.Type) != "http" {
+				continue
+			}
+			path := util.JoinAPIPath(e.Resource, e.Path)
+			route := strings.ToUpper(e.Method) + " " + path
+			if owner, ok := owners[route]; ok {
+				return fmt.Errorf("route '%s' is declared by both function '%s' and function '%s'", route, owner, funcName)
+			}
+			owners[route] = funcName
+
+			// Un solo preflight por recurso: los overrides del mismo path deben coincidir
+			if e.Cors != nil {
+				if other, ok := cors[path]; ok && !reflect.DeepEqual(other, e.Cors) {
+					return fmt.Errorf("conflicting cors overrides for path '%s' in function '%s'", path, funcName)
+				}
+				cors[path] = e.Cors
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *ApiConfig) Validate() error {
+	if a.ResourcePolicy != nil {
+		rules := []struct {
+			name    string
+			sources *PolicySources
+		}{{"allow", a.ResourcePolicy.Allow}, {"deny", a.ResourcePolicy.Deny}}
+
+		for _, rule := range rules {
+			name, sources := rule.name, rule.sources
+			if sources == nil {
+				continue
+			}
+			if len(sources.SourceIps) == 0 && len(sources.VpcEndpoints) == 0 {
+				return fmt.Errorf("api.resourcePolicy.%s requires sourceIps or vpcEndpoints", name)
+			}
+			for _, ip := range sources.SourceIps {
+				if !isValidIPOrCIDR(ip) {
+					return fmt.Errorf("api.resourcePolicy.%s: '%s' is not a valid IP or CIDR", name, ip)
+				}
+			}
+			for _, vpce := range sources.VpcEndpoints {
+				if !reVpcEndpoint.MatchString(vpce) {
+					return fmt.Errorf("api.resourcePolicy.%s: '%s' is not a valid VPC endpoint id (vpce-...)", name, vpce)
+				}
+			}
+		}
+	}
+
+	if a.Cors != nil {
+		if err := a.Cors.Validate(); err != nil {
+			return fmt.Errorf("api.cors: %w", err)
+		}
+	}
+
+	if a.Domain != nil {
+		if err := a.Domain.Validate(); err != nil {
+			return fmt.Errorf("api.domain: %w", err)
+		}
+	}
+
+	for responseType, response := range a.GatewayResponses {
+		if !gatewayResponseTypes[responseType] {
+			return fmt.Errorf("api.gatewayResponses: '%s' is not a valid response type (e.g. DEFAULT_4XX, DEFAULT_5XX, ACCESS_DENIED, THROTTLED)", responseType)
+		}
+		if response.StatusCode != "" && !reStatusCode.MatchString(response.StatusCode) {
+			return fmt.Errorf("api.gatewayResponses.%s: statusCode '%s' is not a valid HTTP status code", responseType, response.StatusCode)
+		}
+	}
+
+	if !validRequestValidation(a.RequestValidation) {
+		return fmt.Errorf("api.requestValidation '%s' must be none, params or all", a.RequestValidation)
+	}
+
 	return nil
 }
 
@@ -85,22 +661,132 @@ func (f *LambdaFunc) Validate(funcName string) error {
 		return fmt.Errorf("functionName is required for function '%s'", funcName)
 	}
 
-	if f.Handler == "" {
+	if len(f.FunctionName) > maxFunctionNameLength {
+		return fmt.Errorf("functionName '%s' exceeds %d characters for function '%s'", f.FunctionName, maxFunctionNameLength, funcName)
+	}
+
+	if f.Handler == "" && !IsProvidedRuntime(f.Runtime) {
 		return fmt.Errorf("handler is required for function '%s'", funcName)
 	}
 
+	// Go: handler puede ser el directorio del paquete main relativo a code
+	if h := filepath.ToSlash(filepath.Clean(f.Handler)); IsProvidedRuntime(f.Runtime) && (filepath.IsAbs(f.Handler) || h == ".." || strings.HasPrefix(h, "../")) {
+		return fmt.Errorf("handler '%s' must be relative to code and stay inside it for function '%s'", f.Handler, funcName)
+	}
+
+	if f.Artifact == "" && f.Code == "" {
+		return fmt.Errorf("code is required for function '%s' (or artifact with a prebuilt zip)", funcName)
+	}
+
+	if f.Artifact != "" {
+		if f.Code != "" || f.SkipInstall || f.ModuleRoot != "" {
+			return fmt.Errorf("artifact cannot be combined with code, skipInstall or moduleRoot for function '%s'", funcName)
+		}
+		if !strings.EqualFold(filepath.Ext(f.Artifact), ".zip") {
+			return fmt.Errorf("artifact '%s' must be a .zip file for function '%s'", f.Artifact, funcName)
+		}
+	}
+
 	if f.Runtime == "" {
-		return fmt.Errorf("runtime is required for function '%s'", funcName)
+		return fmt.Errorf("runtime is required for function '%s' (no provider default set)", funcName)
+	}
+
+	if CanonicalRuntime(f.Runtime) == "" {
+		return fmt.Errorf("runtime '%s' is not supported for function '%s' (supported: %s; go is an alias of provided.al2)",
+			f.Runtime, funcName, strings.Join(SupportedRuntimes(), ", "))
+	}
+
+	// ApplyDefaults ya copió provider.memorySize/timeout: 0 significa que nadie lo definió
+	if f.MemorySize == 0 {
+		return fmt.Errorf("memorySize is required for function '%s' (no provider default set)", funcName)
 	}
 
 	if f.MemorySize < 128 || f.MemorySize > 10240 {
 		return fmt.Errorf("memorySize must be between 128 and 10240 for function '%s'", funcName)
 	}
 
+	if f.Timeout == 0 {
+		return fmt.Errorf("timeout is required for function '%s' (no provider default set)", funcName)
+	}
+
 	if f.Timeout < 1 || f.Timeout > 900 {
 		return fmt.Errorf("timeout must be between 1 and 900 seconds for function '%s'", funcName)
 	}
 
+	if f.Role != "" && f.RoleArn != "" {
+		return fmt.Errorf("role and roleArn cannot both be set for function '%s'", funcName)
+	}
+
+	if role := f.ExecutionRole(); role != "" && !reRoleArn.MatchString(role) {
+		return fmt.Errorf("role '%s' is not a valid IAM role ARN for function '%s'", role, funcName)
+	}
+
+	if rm := f.RuntimeManagement; rm != nil {
+		switch rm.Mode {
+		case RuntimeManagementAuto, RuntimeManagementFunctionUpdate:
+			if rm.RuntimeVersionArn != "" {
+				return fmt.Errorf("runtimeManagement.runtimeVersionArn is only allowed with mode Manual for function '%s'", funcName)
+			}
+		case RuntimeManagementManual:
+			if !reRuntimeVersionArn.MatchString(rm.RuntimeVersionArn) {
+				return fmt.Errorf("runtimeManagement mode Manual requires a valid runtimeVersionArn for function '%s'", funcName)
+			}
+		default:
+			return fmt.Errorf("runtimeManagement.mode '%s' is invalid for function '%s' (expected Auto|FunctionUpdate|Manual)", rm.Mode, funcName)
+		}
+	}
+
+	if err := validateEnvironment(f.Environment); err != nil {
+		return fmt.Errorf("environment of function '%s': %w", funcName, err)
+	}
+
+	if f.KmsKeyArn != "" && !reKmsKeyArn.MatchString(f.KmsKeyArn) {
+		return fmt.Errorf("kmsKeyArn '%s' is not a valid KMS key ARN for function '%s'", f.KmsKeyArn, funcName)
+	}
+
+	if f.Vpc != nil {
+		if err := f.Vpc.Validate(); err != nil {
+			return fmt.Errorf("vpc of function '%s': %w", funcName, err)
+		}
+	}
+
+	if f.Destinations != nil {
+		if err := f.Destinations.Validate(); err != nil {
+			return fmt.Errorf("destinations of function '%s': %w", funcName, err)
+		}
+	}
+
+	if len(f.Layers) > maxLayers {
+		return fmt.Errorf("function '%s' has %d layers (Lambda allows at most %d)", funcName, len(f.Layers), maxLayers)
+	}
+	for _, layer := range f.Layers {
+		if !reLayerVersionArn.MatchString(layer) {
+			return fmt.Errorf("layer '%s' of function '%s' is not a layer version ARN (arn:aws:lambda:<region>:<account>:layer:<name>:<version>)", layer, funcName)
+		}
+	}
+
+	switch f.Architecture {
+	case "", ArchitectureX86_64, ArchitectureArm64:
+	default:
+		return fmt.Errorf("architecture '%s' of function '%s' must be %s or %s", f.Architecture, funcName, ArchitectureX86_64, ArchitectureArm64)
+	}
+
+	// Un rol importado no se modifica (immutable): los statements no tendrían efecto
+	if len(f.IamRoleStatements) > 0 && f.ExecutionRole() != "" {
+		return fmt.Errorf("iamRoleStatements cannot be combined with role in function '%s': add the permissions to the existing role", funcName)
+	}
+	for i, statement := range f.IamRoleStatements {
+		if err := statement.Validate(); err != nil {
+			return fmt.Errorf("iamRoleStatements[%d] of function '%s': %w", i, funcName, err)
+		}
+	}
+
+	for _, stage := range f.Stages {
+		if !isValidServiceName(stage) {
+			return fmt.Errorf("stage '%s' in stages of function '%s' is invalid. Only alphanumeric and hyphens allowed", stage, funcName)
+		}
+	}
+
 	for i, event := range f.Events {
 		if err := event.Validate(funcName, i); err != nil {
 			return err
@@ -116,7 +802,7 @@ func (e *LambdaEvent) Validate(funcName string, index int) error {
 	}
 
 	// Validaciones específicas por tipo de evento
-	switch e.Type {
+	switch strings.ToLower(e.Type) {
 	case "http":
 		if e.Path == "" {
 			return fmt.Errorf("path is required for HTTP events in function '%s'", funcName)
@@ -124,12 +810,330 @@ func (e *LambdaEvent) Validate(funcName string, index int) error {
 		if e.Method == "" {
 			return fmt.Errorf("method is required for HTTP events in function '%s'", funcName)
 		}
+		// Antes de normalizar: las dobles barras se colapsarían sin avisar
+		for _, part := range []string{e.Resource, e.Path} {
+			if strings.Contains(strings.ReplaceAll(part, "\\", "/"), "//") {
+				return fmt.Errorf("path '%s' contains '//' in event %d of function '%s'", part, index, funcName)
+			}
+		}
+		// Misma normalización que usa el engine
+		if err := util.ValidateAPIPath(util.JoinAPIPath(e.Resource, e.Path)); err != nil {
+			return fmt.Errorf("%w in event %d of function '%s'", err, index, funcName)
+		}
+		if e.Cors != nil {
+			if err := e.Cors.Validate(); err != nil {
+				return fmt.Errorf("cors in event %d of function '%s': %w", index, funcName, err)
+			}
+		}
+		if !validRequestValidation(e.RequestValidation) {
+			return fmt.Errorf("requestValidation '%s' in event %d of function '%s' must be none, params or all", e.RequestValidation, index, funcName)
+		}
+		if e.Request != nil {
+			if e.RequestSchema != nil && e.Request.Schema != nil {
+				return fmt.Errorf("requestSchema and request.schema cannot both be set in event %d of function '%s'", index, funcName)
+			}
+			if err := e.Request.Validate(); err != nil {
+				return fmt.Errorf("event %d of function '%s': %w", index, funcName, err)
+			}
+		}
+		if _, err := e.RequestSchemaJSON(); err != nil {
+			return fmt.Errorf("event %d of function '%s': %w", index, funcName, err)
+		}
+	case "schedule":
+		if e.Schedule == "" {
+			return fmt.Errorf("schedule is required for schedule events in function '%s' (event %d)", funcName, index)
+		}
+		if err := validateScheduleExpression(e.Schedule); err != nil {
+			return fmt.Errorf("schedule '%s' in event %d of function '%s': %w", e.Schedule, index, funcName, err)
+		}
+	case "sqs":
+		sources := 0
+		for _, source := range []string{e.Arn, e.Resource, e.QueueName, e.Queue} {
+			if source != "" {
+				sources++
+			}
+		}
+		arn, name := e.QueueArn(), e.SqsQueueName()
+		switch {
+		case sources == 0:
+			return fmt.Errorf("sqs event %d of function '%s' requires queue (ARN or name)", index, funcName)
+		case sources > 1:
+			return fmt.Errorf("sqs event %d of function '%s' must set only one of queue, arn, queueName or resource", index, funcName)
+		case arn != "" && !reSqsArn.MatchString(arn):
+			return fmt.Errorf("queue '%s' in event %d of function '%s' must be an SQS queue ARN", arn, index, funcName)
+		case name != "" && !reQueueName.MatchString(name):
+			return fmt.Errorf("queue '%s' in event %d of function '%s' is not a valid SQS queue name", name, index, funcName)
+		}
+		if e.BatchSize != 0 && (e.BatchSize < 1 || e.BatchSize > 10000) {
+			return fmt.Errorf("batchSize %d in event %d of function '%s' must be between 1 and 10000", e.BatchSize, index, funcName)
+		}
+		if e.MaximumBatchingWindow < 0 || e.MaximumBatchingWindow > 300 {
+			return fmt.Errorf("maximumBatchingWindow %d in event %d of function '%s' must be between 0 and 300 seconds", e.MaximumBatchingWindow, index, funcName)
+		}
+		if e.BatchSize > 10 && e.MaximumBatchingWindow == 0 {
+			return fmt.Errorf("batchSize %d in event %d of function '%s' requires maximumBatchingWindow (SQS allows more than 10 only with a batching window)", e.BatchSize, index, funcName)
+		}
+	case "s3":
+		if !reBucketName.MatchString(e.Bucket) {
+			return fmt.Errorf("bucket '%s' in event %d of function '%s' is not a valid S3 bucket name", e.Bucket, index, funcName)
+		}
+		if S3EventType(e.Event) == "" {
+			return fmt.Errorf("'%s' in event %d of function '%s' is not an S3 notification event (e.g. s3:ObjectCreated:*)", e.Event, index, funcName)
+		}
 		// Puedes agregar más validaciones para otros tipos de eventos
 	}
 
+	if e.Enabled != nil && strings.ToLower(e.Type) != "schedule" {
+		return fmt.Errorf("enabled in event %d of function '%s' only applies to schedule events", index, funcName)
+	}
+
 	return nil
 }
 
+// validateEnvironment rechaza nombres de variables que Lambda no acepta
+func validateEnvironment(env map[string]string) error {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch {
+		case name == "":
+			return fmt.Errorf("empty variable name")
+		case !reEnvVarName.MatchString(name):
+			return fmt.Errorf("variable '%s' is invalid (letters, digits and underscores, starting with a letter)", name)
+		case strings.HasPrefix(strings.ToUpper(name), "AWS"):
+			return fmt.Errorf("variable '%s' uses the reserved AWS prefix", name)
+		case reservedEnvVars[name]:
+			return fmt.Errorf("variable '%s' is reserved by Lambda", name)
+		}
+	}
+	return nil
+}
+
+func (c *CorsConfig) Validate() error {
+	if len(c.AllowOrigins) == 0 {
+		return fmt.Errorf("allowOrigins is required")
+	}
+	for i, origin := range c.AllowOrigins {
+		if strings.TrimSpace(origin) == "" {
+			return fmt.Errorf("allowOrigins[%d] is empty", i)
+		}
+		if strings.ContainsAny(origin, " \t") {
+			return fmt.Errorf("allowOrigins[%d] '%s' contains whitespace", i, origin)
+		}
+	}
+	for i, header := range c.AllowHeaders {
+		if strings.TrimSpace(header) == "" {
+			return fmt.Errorf("allowHeaders[%d] is empty", i)
+		}
+	}
+	// Los navegadores rechazan Access-Control-Allow-Origin: * con credenciales
+	if c.AllowCredentials {
+		for _, origin := range c.AllowOrigins {
+			if origin == "*" {
+				return fmt.Errorf("allowCredentials cannot be combined with allowOrigins '*'")
+			}
+		}
+	}
+	return nil
+}
+
+func (v *VpcConfig) Validate() error {
+	if len(v.SubnetIds) == 0 {
+		return fmt.Errorf("subnetIds is required")
+	}
+	if len(v.SecurityGroupIds) == 0 {
+		return fmt.Errorf("securityGroupIds is required")
+	}
+	for _, id := range v.SubnetIds {
+		if !reSubnetId.MatchString(id) {
+			return fmt.Errorf("'%s' is not a valid subnet id (subnet-...)", id)
+		}
+	}
+	for _, id := range v.SecurityGroupIds {
+		if !reSecurityGroupId.MatchString(id) {
+			return fmt.Errorf("'%s' is not a valid security group id (sg-...)", id)
+		}
+	}
+	return nil
+}
+
+var reVpcEndpoint = regexp.MustCompile(`^vpce-[0-9a-f]+$`)
+
+var reSqsArn = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:sqs:[a-z0-9-]+:\d{12}:[\w-]+(\.fifo)?$`)
+
+var reQueueName = regexp.MustCompile(`^[\w-]{1,80}(\.fifo)?$`)
+
+var reStatusCode = regexp.MustCompile(`^[1-5][0-9]{2}$`)
+
+// Tipos de respuesta de API Gateway que admite gatewayResponses
+var gatewayResponseTypes = map[string]bool{
+	"ACCESS_DENIED": true, "API_CONFIGURATION_ERROR": true, "AUTHORIZER_CONFIGURATION_ERROR": true,
+	"AUTHORIZER_FAILURE": true, "BAD_REQUEST_BODY": true, "BAD_REQUEST_PARAMETERS": true,
+	"DEFAULT_4XX": true, "DEFAULT_5XX": true, "EXPIRED_TOKEN": true, "INTEGRATION_FAILURE": true,
+	"INTEGRATION_TIMEOUT": true, "INVALID_API_KEY": true, "INVALID_SIGNATURE": true,
+	"MISSING_AUTHENTICATION_TOKEN": true, "QUOTA_EXCEEDED": true, "REQUEST_TOO_LARGE": true,
+	"RESOURCE_NOT_FOUND": true, "THROTTLED": true, "UNAUTHORIZED": true,
+	"UNSUPPORTED_MEDIA_TYPE": true, "WAF_FILTERED": true,
+}
+
+var reSubnetId = regexp.MustCompile(`^subnet-[0-9a-f]+$`)
+
+var reSecurityGroupId = regexp.MustCompile(`^sg-[0-9a-f]+$`)
+
+var reEnvVarName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// Variables que Lambda define y no deja sobrescribir (además del prefijo AWS)
+var reservedEnvVars = map[string]bool{"LAMBDA_TASK_ROOT": true, "LAMBDA_RUNTIME_DIR": true}
+
+var reLayerVersionArn = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:lambda:[a-z0-9-]+:\d{12}:layer:[\w-]+:\d+$`)
+
+var reKmsKeyArn = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:kms:[a-z0-9-]+:\d{12}:key/[a-zA-Z0-9-]+$`)
+
+var reRoleArn = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:iam::\d{12}:role/[\w+=,.@/-]+$`)
+
+var reRuntimeVersionArn = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:lambda:[a-z0-9-]+::runtime:[a-f0-9]+$`)
+
+// ExplicitOptionsPaths devuelve los paths con un handler OPTIONS propio: estos
+// tienen prioridad sobre el preflight de CORS generado
+func (c *ServerlessConfig) ExplicitOptionsPaths() map[string]bool {
+	paths := make(map[string]bool)
+	for _, function := range c.ActiveFunctions() {
+		for _, e := range function.Events {
+			if strings.ToLower(e.Type) == "http" && strings.ToUpper(e.Method) == "OPTIONS" {
+				paths[util.JoinAPIPath(e.Resource, e.Path)] = true
+			}
+		}
+	}
+	return paths
+}
+
+// requestValidationMode devuelve el modo del evento o, si no lo fija, el de api
+func (c *ServerlessConfig) requestValidationMode(e LambdaEvent) string {
+	if e.RequestValidation != "" || c.Api == nil {
+		return e.RequestValidation
+	}
+	return c.Api.RequestValidation
+}
+
+// Warnings devuelve avisos no bloqueantes sobre la configuración
+// (rutas sospechosas, etc.). validate los muestra; el modo estricto los trata como errores.
+func (c *ServerlessConfig) Warnings() []string {
+	var warnings []string
+	explicitOptions := c.ExplicitOptionsPaths()
+
+	// El dominio es regional: el certificado debe estar en la región del API
+	if c.Api != nil && c.Api.Domain != nil && c.Provider != nil && c.Provider.Region != "" {
+		if region := c.Api.Domain.CertificateRegion(); region != "" && region != c.Provider.Region {
+			warnings = append(warnings, fmt.Sprintf("api.domain certificate is in %s but provider.region is %s: a regional domain needs the certificate in the API's region",
+				region, c.Provider.Region))
+		}
+	}
+
+	for _, funcName := range SortedFunctionNames(c.Functions) {
+		function := c.Functions[funcName]
+		for i, e := range function.Events {
+			// CDK solo concede el consumo de la cola a los roles que crea
+			if strings.ToLower(e.Type) == "sqs" && function.ExecutionRole() != "" {
+				warnings = append(warnings, fmt.Sprintf("sqs event %d of function '%s' uses the existing role '%s': it must allow sqs:ReceiveMessage, sqs:DeleteMessage, sqs:ChangeMessageVisibility and sqs:GetQueueAttributes on the queue",
+					i, funcName, function.ExecutionRole()))
+			}
+			if strings.ToLower(e.Type) != "http" {
+				continue
+			}
+			if e.Resource != "" && !strings.HasPrefix(e.Resource, "/") {
+				warnings = append(warnings, fmt.Sprintf("resource '%s' in event %d of function '%s' has no leading '/'", e.Resource, i, funcName))
+			}
+			if e.Path != "" && !strings.HasPrefix(e.Path, "/") && !strings.HasPrefix(e.Path, "{") {
+				warnings = append(warnings, fmt.Sprintf("path '%s' in event %d of function '%s' has no leading '/'", e.Path, i, funcName))
+			}
+			if e.Cors != nil && explicitOptions[util.JoinAPIPath(e.Resource, e.Path)] {
+				warnings = append(warnings, fmt.Sprintf("cors in event %d of function '%s' is ignored: path '%s' has an explicit OPTIONS handler",
+					i, funcName, util.JoinAPIPath(e.Resource, e.Path)))
+			}
+			// Un schema sin modo explícito activa la validación all; params o none lo ignoran
+			mode := c.requestValidationMode(e)
+			if e.bodySchema() != nil && mode != "" && mode != RequestValidationAll {
+				warnings = append(warnings, fmt.Sprintf("requestSchema in event %d of function '%s' is not enforced: requestValidation is '%s'",
+					i, funcName, mode))
+			}
+			if e.Request.HasRequiredParams() && mode == RequestValidationNone {
+				warnings = append(warnings, fmt.Sprintf("required request parameters in event %d of function '%s' are not enforced: requestValidation is 'none'",
+					i, funcName))
+			}
+			if len(e.Resource) > 1 && strings.HasSuffix(e.Resource, "/") && e.Path != "" && e.Path != "/" {
+				warnings = append(warnings, fmt.Sprintf("resource '%s' ends with '/' and is joined with path '%s' as '%s' in function '%s'",
+					e.Resource, e.Path, util.JoinAPIPath(e.Resource, e.Path), funcName))
+			}
+		}
+	}
+
+	return append(warnings, c.shadowedRouteWarnings()...)
+}
+
+// Una ruta {proxy+} (sobre todo con método ANY) cubre todo lo que cuelga de su
+// prefijo: avisa de las rutas más específicas declaradas bajo ese prefijo
+func (c *ServerlessConfig) shadowedRouteWarnings() []string {
+	type route struct {
+		method, path, funcName string
+	}
+
+	var routes []route
+	active := c.ActiveFunctions()
+	for _, funcName := range SortedFunctionNames(active) {
+		for _, e := range active[funcName].Events {
+			if strings.ToLower(e.Type) == "http" {
+				routes = append(routes, route{strings.ToUpper(e.Method), util.JoinAPIPath(e.Resource, e.Path), funcName})
+			}
+		}
+	}
+
+	var warnings []string
+	for _, proxy := range routes {
+		prefix, ok := util.GreedyPrefix(proxy.path)
+		if !ok {
+			continue
+		}
+		for _, r := range routes {
+			if r.path == proxy.path || r.method == "OPTIONS" {
+				continue
+			}
+			if proxy.method != "ANY" && proxy.method != r.method {
+				continue
+			}
+			if prefix != "/" && !strings.HasPrefix(r.path, prefix+"/") {
+				continue
+			}
+			if prefix == "/" && r.path == "/" {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("route '%s %s' of function '%s' is under greedy route '%s %s' of function '%s' and may be shadowed by it",
+				r.method, r.path, r.funcName, proxy.method, proxy.path, proxy.funcName))
+		}
+	}
+	return warnings
+}
+
+// SortedFunctionNames devuelve los nombres lógicos en orden estable, para que
+// synth genere siempre el mismo template con la misma configuración
+func SortedFunctionNames(functions map[string]LambdaFunc) []string {
+	keys := make([]string, 0, len(functions))
+	for k := range functions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isValidIPOrCIDR(s string) bool {
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return true
+	}
+	return net.ParseIP(s) != nil
+}
+
 func isValidServiceName(name string) bool {
 	// Solo letras, números y guiones
 	match, _ := regexp.MatchString("^[a-zA-Z0-9-]+$", name)
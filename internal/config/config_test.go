@@ -0,0 +1,177 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// validConfig devuelve una configuración que pasa Validate
+func validConfig() *ServerlessConfig {
+	return &ServerlessConfig{
+		Service: "orders-api",
+		Stage:   "dev",
+		Functions: map[string]LambdaFunc{
+			"create": {
+				FunctionName: "create-order",
+				Runtime:      "nodejs20.x",
+				Handler:      "index.handler",
+				Code:         "src/create",
+				MemorySize:   256,
+				Timeout:      10,
+			},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	withFunction := func(edit func(f *LambdaFunc)) func(c *ServerlessConfig) {
+		return func(c *ServerlessConfig) {
+			f := c.Functions["create"]
+			edit(&f)
+			c.Functions["create"] = f
+		}
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(c *ServerlessConfig)
+		wantErr string
+	}{
+		{name: "valid", edit: func(c *ServerlessConfig) {}},
+		{name: "missing service", edit: func(c *ServerlessConfig) { c.Service = "" },
+			wantErr: "field 'service' is required"},
+		{name: "invalid service name", edit: func(c *ServerlessConfig) { c.Service = "orders_api!" },
+			wantErr: "service name 'orders_api!' is invalid"},
+		{name: "missing stage", edit: func(c *ServerlessConfig) { c.Stage = "" },
+			wantErr: "field 'stage' is required"},
+		{name: "no functions", edit: func(c *ServerlessConfig) { c.Functions = nil },
+			wantErr: "at least one function must be defined"},
+		{name: "missing functionName", edit: withFunction(func(f *LambdaFunc) { f.FunctionName = "" }),
+			wantErr: "functionName is required for function 'create'"},
+		{name: "missing runtime", edit: withFunction(func(f *LambdaFunc) { f.Runtime = "" }),
+			wantErr: "runtime is required for function 'create'"},
+		{name: "missing handler", edit: withFunction(func(f *LambdaFunc) { f.Handler = "" }),
+			wantErr: "handler is required for function 'create'"},
+		{name: "provided runtime without handler", edit: withFunction(func(f *LambdaFunc) { f.Runtime, f.Handler = "provided.al2023", "" })},
+		{name: "double slash in path", edit: withFunction(func(f *LambdaFunc) {
+			f.Events = []LambdaEvent{{Type: "http", Method: "get", Resource: "/api", Path: "/users//{id}"}}
+		}),
+			wantErr: "path '/users//{id}' contains '//' in event 0 of function 'create'"},
+		{name: "missing code", edit: withFunction(func(f *LambdaFunc) { f.Code = "" }),
+			wantErr: "code is required for function 'create'"},
+		{name: "memorySize below 128", edit: withFunction(func(f *LambdaFunc) { f.MemorySize = 64 }),
+			wantErr: "memorySize must be between 128 and 10240 for function 'create'"},
+		{name: "memorySize above 10240", edit: withFunction(func(f *LambdaFunc) { f.MemorySize = 10241 }),
+			wantErr: "memorySize must be between 128 and 10240 for function 'create'"},
+		{name: "memorySize at the bounds", edit: withFunction(func(f *LambdaFunc) { f.MemorySize = 10240 })},
+		{name: "missing timeout", edit: withFunction(func(f *LambdaFunc) { f.Timeout = 0 }),
+			wantErr: "timeout is required for function 'create'"},
+		{name: "timeout above 900", edit: withFunction(func(f *LambdaFunc) { f.Timeout = 901 }),
+			wantErr: "timeout must be between 1 and 900 seconds for function 'create'"},
+		{name: "timeout below 1", edit: withFunction(func(f *LambdaFunc) { f.Timeout = -1 }),
+			wantErr: "timeout must be between 1 and 900 seconds for function 'create'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.edit(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Con varias funciones inválidas Validate reporta siempre la primera por nombre
+func TestValidateReportsFunctionsInStableOrder(t *testing.T) {
+	cfg := validConfig()
+	for _, name := range []string{"zeta", "alpha", "mid"} {
+		f := cfg.Functions["create"]
+		f.FunctionName = name
+		f.Code = ""
+		cfg.Functions[name] = f
+	}
+
+	for i := 0; i < 20; i++ {
+		err := cfg.Validate()
+		if err == nil || err.Error() != "code is required for function 'alpha' (or artifact with a prebuilt zip)" {
+			t.Fatalf("Validate() = %v, want the error of function 'alpha'", err)
+		}
+	}
+}
+
+func TestValidateProviderBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider ProviderConfig
+		wantErr  string
+	}{
+		{name: "memorySize below 128", provider: ProviderConfig{MemorySize: 127},
+			wantErr: "provider.memorySize must be between 128 and 10240"},
+		{name: "memorySize above 10240", provider: ProviderConfig{MemorySize: 20480},
+			wantErr: "provider.memorySize must be between 128 and 10240"},
+		{name: "timeout above 900", provider: ProviderConfig{Timeout: 1000},
+			wantErr: "provider.timeout must be between 1 and 900 seconds"},
+		{name: "defaults within bounds", provider: ProviderConfig{MemorySize: 128, Timeout: 900}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Provider = &tt.provider
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPathWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		path     string
+		want     string
+	}{
+		{name: "clean", resource: "/api", path: "/users"},
+		{name: "resource without leading slash", resource: "api", path: "/users",
+			want: "resource 'api' in event 0 of function 'create' has no leading '/'"},
+		{name: "path without leading slash", path: "users",
+			want: "path 'users' in event 0 of function 'create' has no leading '/'"},
+		{name: "path parameter without leading slash", path: "{id}"},
+		{name: "trailing slash on resource", resource: "/api/", path: "/users",
+			want: "resource '/api/' ends with '/' and is joined with path '/users' as '/api/users' in function 'create'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			f := cfg.Functions["create"]
+			f.Events = []LambdaEvent{{Type: "http", Method: "get", Resource: tt.resource, Path: tt.path}}
+			cfg.Functions["create"] = f
+
+			warnings := cfg.Warnings()
+			if tt.want == "" {
+				if len(warnings) != 0 {
+					t.Fatalf("Warnings() = %v, want none", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || warnings[0] != tt.want {
+				t.Fatalf("Warnings() = %q, want [%q]", warnings, tt.want)
+			}
+		})
+	}
+}
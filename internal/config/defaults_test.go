@@ -0,0 +1,242 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadYAML carga y valida un config escrito en un directorio temporal
+func loadYAML(t *testing.T, doc string) (*ServerlessConfig, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "qrioso-sls.yml")
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadStage(path, "dev")
+	if err != nil {
+		return nil, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func TestFunctionInheritsProviderRuntime(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		wantRuntime string
+		wantHandler string
+		wantErr     string
+	}{
+		{
+			name: "function omits runtime",
+			doc: `service: svc
+provider: {runtime: python3.12, memorySize: 128, timeout: 10}
+functions:
+  worker: {functionName: worker, handler: main.handler, code: src}
+`,
+			wantRuntime: "python3.12",
+			wantHandler: "main.handler",
+		},
+		{
+			name: "function runtime overrides the provider",
+			doc: `service: svc
+provider: {runtime: python3.12, memorySize: 128, timeout: 10}
+functions:
+  worker: {functionName: worker, runtime: nodejs20.x, handler: index.handler, code: src}
+`,
+			wantRuntime: "nodejs20.x",
+			wantHandler: "index.handler",
+		},
+		{
+			name: "inherited provided runtime defaults the handler",
+			doc: `service: svc
+provider: {runtime: go, memorySize: 128, timeout: 10}
+functions:
+  worker: {functionName: worker, code: src}
+`,
+			wantRuntime: "go",
+			wantHandler: ProvidedHandler,
+		},
+		{
+			name: "inherited runtime is validated",
+			doc: `service: svc
+provider: {runtime: node16, memorySize: 128, timeout: 10}
+functions:
+  worker: {functionName: worker, handler: index.handler, code: src}
+`,
+			wantErr: "runtime 'node16' is not supported for function 'worker'",
+		},
+		{
+			name: "no runtime anywhere",
+			doc: `service: svc
+provider: {memorySize: 128, timeout: 10}
+functions:
+  worker: {functionName: worker, handler: index.handler, code: src}
+`,
+			wantErr: "runtime is required for function 'worker' (no provider default set)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadYAML(t, tt.doc)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			fn := cfg.Functions["worker"]
+			if fn.Runtime != tt.wantRuntime || fn.Handler != tt.wantHandler {
+				t.Errorf("runtime, handler = %q, %q, want %q, %q", fn.Runtime, fn.Handler, tt.wantRuntime, tt.wantHandler)
+			}
+		})
+	}
+}
+
+func TestMemoryAndTimeoutDefaults(t *testing.T) {
+	const function = `functions:
+  worker:
+    functionName: worker
+    handler: index.handler
+    code: src
+`
+	tests := []struct {
+		name        string
+		provider    string
+		function    string
+		wantMemory  int
+		wantTimeout int
+		wantErr     string
+	}{
+		{
+			name:        "function sets them",
+			function:    "    memorySize: 512\n    timeout: 30\n",
+			wantMemory:  512,
+			wantTimeout: 30,
+		},
+		{
+			name:        "provider sets them",
+			provider:    "  memorySize: 256\n  timeout: 20\n",
+			wantMemory:  256,
+			wantTimeout: 20,
+		},
+		{
+			name:        "function overrides the provider",
+			provider:    "  memorySize: 256\n  timeout: 20\n",
+			function:    "    memorySize: 1024\n",
+			wantMemory:  1024,
+			wantTimeout: 20,
+		},
+		{
+			name:     "neither sets memorySize",
+			provider: "  timeout: 20\n",
+			wantErr:  "memorySize is required for function 'worker' (no provider default set)",
+		},
+		{
+			name:     "neither sets timeout",
+			provider: "  memorySize: 256\n",
+			wantErr:  "timeout is required for function 'worker' (no provider default set)",
+		},
+		{
+			name:     "provider default out of range",
+			provider: "  memorySize: 64\n  timeout: 20\n",
+			wantErr:  "provider.memorySize must be between 128 and 10240",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := "service: svc\nprovider:\n  runtime: nodejs20.x\n" + tt.provider + function + tt.function
+			cfg, err := loadYAML(t, doc)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			fn := cfg.Functions["worker"]
+			if fn.MemorySize != tt.wantMemory || fn.Timeout != tt.wantTimeout {
+				t.Errorf("memorySize, timeout = %d, %d, want %d, %d", fn.MemorySize, fn.Timeout, tt.wantMemory, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestProviderEnvironmentDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		function string
+		want     map[string]string
+	}{
+		{
+			name:     "inherited",
+			provider: "  environment: {TABLE: orders, LOG_LEVEL: info}\n",
+			want:     map[string]string{"TABLE": "orders", "LOG_LEVEL": "info"},
+		},
+		{
+			name:     "partial override",
+			provider: "  environment: {TABLE: orders, LOG_LEVEL: info}\n",
+			function: "    environment: {LOG_LEVEL: debug, FEATURE: on}\n",
+			want:     map[string]string{"TABLE": "orders", "LOG_LEVEL": "debug", "FEATURE": "on"},
+		},
+		{
+			name:     "empty provider environment",
+			provider: "  environment: {}\n",
+			function: "    environment: {FEATURE: on}\n",
+			want:     map[string]string{"FEATURE": "on"},
+		},
+		{
+			name: "no provider environment",
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := "service: svc\nprovider:\n  runtime: nodejs20.x\n  memorySize: 128\n  timeout: 10\n" + tt.provider +
+				"functions:\n  worker:\n    functionName: worker\n    handler: index.handler\n    code: src\n" + tt.function +
+				"  other:\n    functionName: other\n    handler: index.handler\n    code: src\n"
+			cfg, err := loadYAML(t, doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := cfg.Functions["worker"].Environment
+			if len(got) != len(tt.want) {
+				t.Fatalf("environment = %v, want %v", got, tt.want)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Errorf("environment[%s] = %q, want %q", name, got[name], value)
+				}
+			}
+
+			// Cada función tiene su propio map: un override no alcanza a las demás
+			if v, ok := cfg.Functions["other"].Environment["FEATURE"]; ok {
+				t.Errorf("function 'other' got FEATURE=%q from another function", v)
+			}
+		})
+	}
+}
+
+// Un provider vacío no cambia nada de las funciones
+func TestEmptyProviderBlock(t *testing.T) {
+	cfg, err := loadYAML(t, `service: svc
+provider: {}
+functions:
+  worker: {functionName: worker, runtime: python3.12, handler: main.handler, code: src, memorySize: 256, timeout: 15, environment: {A: b}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := cfg.Functions["worker"]
+	if fn.Runtime != "python3.12" || fn.MemorySize != 256 || fn.Timeout != 15 || len(fn.Environment) != 1 || fn.Environment["A"] != "b" {
+		t.Errorf("function changed by an empty provider: %+v", fn)
+	}
+}
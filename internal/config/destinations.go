@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Destinos de las invocaciones asíncronas de una función
+type DestinationsConfig struct {
+	OnSuccess string `yaml:"onSuccess,omitempty"`
+	OnFailure string `yaml:"onFailure,omitempty"`
+}
+
+// Servicios que Lambda admite como destino
+const (
+	DestinationSqs         = "sqs"
+	DestinationSns         = "sns"
+	DestinationEventBridge = "events"
+	DestinationLambda      = "lambda"
+)
+
+var reDestinationArn = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:(sqs|sns|events|lambda):[a-z0-9-]+:\d{12}:(.+)$`)
+
+// events y lambda tienen otros tipos de recurso (reglas, layers...)
+var (
+	reEventBusResource = regexp.MustCompile(`^event-bus/[\w.\-/]+$`)
+	reFunctionResource = regexp.MustCompile(`^function:[\w-]+(:[\w$-]+)?$`)
+)
+
+// DestinationService devuelve el servicio del ARN de destino (sqs, sns,
+// events, lambda) o "" si no es un destino válido
+func DestinationService(arn string) string {
+	m := reDestinationArn.FindStringSubmatch(arn)
+	if m == nil {
+		return ""
+	}
+
+	switch service, resource := m[1], m[2]; service {
+	case DestinationEventBridge:
+		if !reEventBusResource.MatchString(resource) {
+			return ""
+		}
+	case DestinationLambda:
+		if !reFunctionResource.MatchString(resource) {
+			return ""
+		}
+	}
+	return m[1]
+}
+
+// DestinationFunctionName devuelve el nombre de la función de un destino
+// lambda (sin alias ni versión), o "" si el destino no es una función
+func DestinationFunctionName(arn string) string {
+	if DestinationService(arn) != DestinationLambda {
+		return ""
+	}
+	resource := reDestinationArn.FindStringSubmatch(arn)[2]
+	return strings.SplitN(strings.TrimPrefix(resource, "function:"), ":", 2)[0]
+}
+
+func (d *DestinationsConfig) Validate() error {
+	for _, dest := range []struct{ name, arn string }{{"onSuccess", d.OnSuccess}, {"onFailure", d.OnFailure}} {
+		if dest.arn != "" && DestinationService(dest.arn) == "" {
+			return fmt.Errorf("%s '%s' must be an SQS queue, SNS topic, EventBridge bus or Lambda function ARN", dest.name, dest.arn)
+		}
+	}
+	return nil
+}
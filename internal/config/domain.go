@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dominio propio del API (solo en el stack desplegado, no en local)
+type DomainConfig struct {
+	DomainName     string `yaml:"domainName"`
+	CertificateArn string `yaml:"certificateArn,omitempty"` // Certificado de ACM en la región del API (endpoint regional)
+	BasePath       string `yaml:"basePath,omitempty"`       // Vacío = el API en la raíz del dominio
+}
+
+// Solo el host: sin esquema, puerto ni path
+var reDomainName = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+var reCertificateArn = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:acm:([a-z0-9-]+):\d{12}:certificate/[\w-]+$`)
+
+var reBasePath = regexp.MustCompile(`^[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+func (d *DomainConfig) Validate() error {
+	if d.DomainName == "" {
+		return fmt.Errorf("domainName is required")
+	}
+	if strings.Contains(d.DomainName, "://") || strings.ContainsAny(d.DomainName, "/:") {
+		return fmt.Errorf("domainName '%s' must be a host name only (no scheme, port or path)", d.DomainName)
+	}
+	if !reDomainName.MatchString(d.DomainName) {
+		return fmt.Errorf("domainName '%s' is not a valid lowercase domain name", d.DomainName)
+	}
+	if d.CertificateArn == "" {
+		return fmt.Errorf("certificateArn is required for domain '%s'", d.DomainName)
+	}
+	if !reCertificateArn.MatchString(d.CertificateArn) {
+		return fmt.Errorf("certificateArn '%s' is not a valid ACM certificate ARN", d.CertificateArn)
+	}
+	if d.BasePath != "" && !reBasePath.MatchString(d.BasePath) {
+		return fmt.Errorf("basePath '%s' must be path segments without leading or trailing '/' (e.g. v1)", d.BasePath)
+	}
+	return nil
+}
+
+// CertificateRegion devuelve la región del certificado de ACM
+func (d *DomainConfig) CertificateRegion() string {
+	if m := reCertificateArn.FindStringSubmatch(d.CertificateArn); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// resolveStageEnvironment resuelve los valores de environment del provider y
+// de cada función que son maps por stage (TABLE: {dev: dev-table, prod: prod-table})
+// al valor del stage activo. Los escalares valen para todos los stages.
+// Las funciones que no se despliegan en el stage también se resuelven (para
+// que el config se pueda leer), pero sin exigir un valor para el stage.
+func resolveStageEnvironment(doc map[string]interface{}) error {
+	stage, _ := doc["stage"].(string)
+
+	if provider, ok := doc["provider"].(map[string]interface{}); ok {
+		if env, ok := provider["environment"].(map[string]interface{}); ok {
+			if err := resolveStageValues(env, stage, true); err != nil {
+				return fmt.Errorf("provider: %w", err)
+			}
+		}
+	}
+
+	functions, _ := doc["functions"].(map[string]interface{})
+	for funcName, fn := range functions {
+		fnMap, ok := fn.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		env, ok := fnMap["environment"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		active := listsStage(fnMap["stages"], stage) && fnMap["enabled"] != false
+		if err := resolveStageValues(env, stage, active); err != nil {
+			return fmt.Errorf("function '%s': %w", funcName, err)
+		}
+	}
+	return nil
+}
+
+// resolveStageValues reemplaza en env cada map por stage por el valor de stage.
+// Sin valor para el stage es un error si required; si no, la variable se quita.
+func resolveStageValues(env map[string]interface{}, stage string, required bool) error {
+	for name, value := range env {
+		byStage, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for stageName, v := range byStage {
+			if _, ok := v.(string); !ok {
+				return fmt.Errorf("environment.%s.%s must be a string", name, stageName)
+			}
+		}
+
+		v, ok := byStage[stage]
+		if !ok && !required {
+			delete(env, name)
+			continue
+		}
+		if !ok {
+			stages := make([]string, 0, len(byStage))
+			for stageName := range byStage {
+				stages = append(stages, stageName)
+			}
+			sort.Strings(stages)
+			return fmt.Errorf("environment.%s has no value for stage '%s' (defined: %v)", name, stage, stages)
+		}
+		env[name] = v
+	}
+	return nil
+}
+
+// listsStage indica si la función se despliega en stage según su lista stages
+// (sin lista = todos)
+func listsStage(stages interface{}, stage string) bool {
+	list, ok := stages.([]interface{})
+	if !ok || len(list) == 0 {
+		return true
+	}
+	for _, s := range list {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
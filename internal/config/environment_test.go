@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig escribe un config mínimo con el bloque functions dado
+func writeConfig(t *testing.T, functions string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "qrioso-sls.yml")
+	doc := "service: svc\nstage: dev\nprovider: {runtime: nodejs20.x, memorySize: 128, timeout: 10}\nfunctions:\n" + functions
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStageEnvironment(t *testing.T) {
+	const prodOnly = `  reports:
+    functionName: reports
+    handler: index.handler
+    code: src
+    stages: [prod]
+    environment:
+      TABLE: {prod: prod-table}
+      REGION: us-east-1
+`
+	const everyStage = `  api:
+    functionName: api
+    handler: index.handler
+    code: src
+    environment:
+      TABLE: {prod: prod-table}
+`
+	tests := []struct {
+		name      string
+		functions string
+		stage     string
+		function  string
+		want      map[string]string
+		wantErr   string
+	}{
+		{name: "inactive function without a value for the stage loads", functions: prodOnly, stage: "dev",
+			function: "reports", want: map[string]string{"REGION": "us-east-1"}},
+		{name: "active function gets the stage value", functions: prodOnly, stage: "prod",
+			function: "reports", want: map[string]string{"TABLE": "prod-table", "REGION": "us-east-1"}},
+		{name: "active function without a value for the stage fails", functions: everyStage, stage: "dev",
+			wantErr: "environment.TABLE has no value for stage 'dev'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadStage(writeConfig(t, tt.functions), tt.stage)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadStage error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadStage: %v", err)
+			}
+			got := cfg.Functions[tt.function].Environment
+			if len(got) != len(tt.want) {
+				t.Fatalf("environment = %v, want %v", got, tt.want)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Errorf("environment[%s] = %q, want %q", name, got[name], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qrioso-software/qriososls/internal/util"
+)
+
+// ApplyExclusive deja solo las funciones indicadas, para sintetizar un stack
+// reducido mientras se itera sobre un endpoint. Las rutas y recursos de las
+// demás desaparecen con ellas porque se generan a partir de sus eventos.
+func (c *ServerlessConfig) ApplyExclusive(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	active := c.ActiveFunctions()
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := c.Functions[name]; !ok {
+			return fmt.Errorf("function '%s' in --exclusively is not defined (available: %s)",
+				name, strings.Join(SortedFunctionNames(c.Functions), ", "))
+		}
+		if _, ok := active[name]; !ok {
+			return fmt.Errorf("function '%s' in --exclusively is disabled or not deployed in stage '%s'", name, c.Stage)
+		}
+		keep[name] = true
+	}
+
+	if err := c.checkExclusiveDependencies(active, keep); err != nil {
+		return err
+	}
+
+	for funcName := range c.Functions {
+		if !keep[funcName] {
+			delete(c.Functions, funcName)
+		}
+	}
+	return nil
+}
+
+// Un destino lambda a otra función del servicio que --exclusively deja fuera
+// apuntaría a una función que el stack reducido ya no tiene
+func (c *ServerlessConfig) checkExclusiveDependencies(active map[string]LambdaFunc, keep map[string]bool) error {
+	dropped := make(map[string]string) // Nombre desplegado -> clave de la función
+	for funcName, function := range active {
+		if !keep[funcName] {
+			dropped[util.ResolveVars(function.FunctionName, c.Stage)] = funcName
+		}
+	}
+
+	for _, funcName := range SortedFunctionNames(active) {
+		dest := active[funcName].Destinations
+		if !keep[funcName] || dest == nil {
+			continue
+		}
+		for _, d := range []struct{ name, arn string }{{"onSuccess", dest.OnSuccess}, {"onFailure", dest.OnFailure}} {
+			name := DestinationFunctionName(d.arn)
+			if target, ok := dropped[name]; ok && name != "" {
+				return fmt.Errorf("function '%s' in --exclusively sends %s to function '%s', which --exclusively leaves out: add '%s' to --exclusively",
+					funcName, d.name, target, target)
+			}
+		}
+	}
+	return nil
+}
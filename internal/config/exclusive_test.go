@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// exclusiveConfig: api manda sus fallos a dlq, report es independiente
+func exclusiveConfig() *ServerlessConfig {
+	fn := func(name string, dest *DestinationsConfig) LambdaFunc {
+		return LambdaFunc{FunctionName: "svc-${stage}-" + name, Runtime: "nodejs20.x", Handler: "index.handler", Code: "src/" + name, Destinations: dest}
+	}
+	return &ServerlessConfig{
+		Service: "svc",
+		Stage:   "dev",
+		Functions: map[string]LambdaFunc{
+			"api":    fn("api", &DestinationsConfig{OnFailure: "arn:aws:lambda:us-east-1:123456789012:function:svc-dev-dlq:live"}),
+			"dlq":    fn("dlq", nil),
+			"report": fn("report", &DestinationsConfig{OnSuccess: "arn:aws:sqs:us-east-1:123456789012:reports"}),
+			"legacy": {FunctionName: "legacy", Runtime: "nodejs20.x", Handler: "index.handler", Code: "src/legacy", Stages: []string{"prod"}},
+		},
+	}
+}
+
+func TestApplyExclusive(t *testing.T) {
+	tests := []struct {
+		name    string
+		keep    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "no selection keeps everything", want: []string{"api", "dlq", "legacy", "report"}},
+		{name: "independent function", keep: []string{"report"}, want: []string{"report"}},
+		{name: "function with its destination", keep: []string{"api", "dlq"}, want: []string{"api", "dlq"}},
+		{name: "destination target alone", keep: []string{"dlq"}, want: []string{"dlq"}},
+		{name: "destination left out", keep: []string{"api"},
+			wantErr: "function 'api' in --exclusively sends onFailure to function 'dlq', which --exclusively leaves out: add 'dlq' to --exclusively"},
+		{name: "unknown function", keep: []string{"nope"},
+			wantErr: "function 'nope' in --exclusively is not defined (available: api, dlq, legacy, report)"},
+		{name: "function outside the stage", keep: []string{"legacy"},
+			wantErr: "function 'legacy' in --exclusively is disabled or not deployed in stage 'dev'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := exclusiveConfig()
+			err := cfg.ApplyExclusive(tt.keep)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ApplyExclusive = %v, want %q", err, tt.wantErr)
+				}
+				if len(cfg.Functions) != 4 {
+					t.Errorf("a rejected selection pruned functions: %v", SortedFunctionNames(cfg.Functions))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(SortedFunctionNames(cfg.Functions), ","); got != strings.Join(tt.want, ",") {
+				t.Errorf("functions = %s, want %s", got, strings.Join(tt.want, ","))
+			}
+		})
+	}
+}
+
+func TestDestinationFunctionName(t *testing.T) {
+	tests := map[string]string{
+		"arn:aws:lambda:us-east-1:123456789012:function:svc-dev-dlq":         "svc-dev-dlq",
+		"arn:aws:lambda:us-east-1:123456789012:function:svc-dev-dlq:live":    "svc-dev-dlq",
+		"arn:aws:lambda:us-east-1:123456789012:function:svc-dev-dlq:$LATEST": "svc-dev-dlq",
+		"arn:aws:sqs:us-east-1:123456789012:svc-dev-dlq":                     "",
+		"": "",
+	}
+	for arn, want := range tests {
+		if got := DestinationFunctionName(arn); got != want {
+			t.Errorf("DestinationFunctionName(%q) = %q, want %q", arn, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Statement IAM que se agrega al rol de ejecución de la función
+type IamRoleStatement struct {
+	Effect   string   `yaml:"effect"`
+	Action   []string `yaml:"action"`
+	Resource []string `yaml:"resource"`
+}
+
+// Efectos admitidos por IAM
+const (
+	EffectAllow = "Allow"
+	EffectDeny  = "Deny"
+)
+
+// servicio:Acción, con comodines (s3:Get*, dynamodb:*)
+var reIamAction = regexp.MustCompile(`^[a-z0-9-]+:[A-Za-z0-9*?]+$`)
+
+func (s *IamRoleStatement) Validate() error {
+	if s.Effect != EffectAllow && s.Effect != EffectDeny {
+		return fmt.Errorf("effect '%s' must be %s or %s", s.Effect, EffectAllow, EffectDeny)
+	}
+	if len(s.Action) == 0 {
+		return fmt.Errorf("action must list at least one action")
+	}
+	for _, action := range s.Action {
+		if action != "*" && !reIamAction.MatchString(action) {
+			return fmt.Errorf("action '%s' must be service:Action (e.g. dynamodb:GetItem)", action)
+		}
+	}
+	if len(s.Resource) == 0 {
+		return fmt.Errorf("resource must list at least one ARN or '*'")
+	}
+	for _, resource := range s.Resource {
+		if resource != "*" && !strings.HasPrefix(resource, "arn:") {
+			return fmt.Errorf("resource '%s' must be an ARN or '*'", resource)
+		}
+	}
+	return nil
+}
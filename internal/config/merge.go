@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/qrioso-software/qriososls/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// deepMerge mezcla src sobre dst: los maps se mezclan recursivamente y
+// cualquier otro valor (incluidas las listas) se reemplaza
+func deepMerge(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			dst[key] = deepMerge(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// applyStageOverrides aplica el bloque stages.<stage> sobre la configuración base.
+// Si stage está vacío se usa el stage definido en el archivo.
+func applyStageOverrides(doc map[string]interface{}, stage string) (map[string]interface{}, error) {
+	if stage == "" {
+		stage, _ = doc["stage"].(string)
+	}
+
+	stages, _ := doc["stages"].(map[string]interface{})
+	delete(doc, "stages")
+
+	if stage != "" {
+		doc["stage"] = stage
+	}
+
+	if override, ok := stages[stage]; ok && override != nil {
+		overrideMap, ok := override.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("stages.%s must be a mapping", stage)
+		}
+		// El override no puede cambiar el stage al que se aplica
+		delete(overrideMap, "stage")
+		doc = deepMerge(doc, overrideMap)
+	}
+
+	return doc, nil
+}
+
+// StageNames devuelve todos los stages mencionados en el archivo: el stage base,
+// las claves de stages y los stages listados en cada función (ordenados)
+func StageNames(paths ...string) ([]string, error) {
+	doc, err := readDocuments(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	if stage, ok := doc["stage"].(string); ok && stage != "" {
+		seen[stage] = true
+	}
+	if stages, ok := doc["stages"].(map[string]interface{}); ok {
+		for stage := range stages {
+			seen[stage] = true
+		}
+	}
+	if functions, ok := doc["functions"].(map[string]interface{}); ok {
+		for _, fn := range functions {
+			fnMap, _ := fn.(map[string]interface{})
+			list, _ := fnMap["stages"].([]interface{})
+			for _, stage := range list {
+				if s, ok := stage.(string); ok && s != "" {
+					seen[s] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for stage := range seen {
+		names = append(names, stage)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// readDocuments mezcla los archivos en orden con deepMerge: los maps se
+// combinan y las listas del último archivo reemplazan a las anteriores
+func readDocuments(paths []string) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	for _, path := range paths {
+		overlay, err := readDocument(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		doc = deepMerge(doc, overlay)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	return doc, nil
+}
+
+// sourceHash es el sha256 del contenido de los archivos de config, en orden
+func sourceHash(paths []string) (string, error) {
+	var content strings.Builder
+	for _, path := range paths {
+		b, err := readFile(path)
+		if err != nil {
+			return "", err
+		}
+		content.Write(b)
+	}
+	return util.Sha256Hash(content.String()), nil
+}
+
+// readDocument lee el YAML como un map genérico para poder mezclarlo
+func readDocument(path string) (map[string]interface{}, error) {
+	b, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	return doc, nil
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion es la versión del esquema que escribe migrate. Los archivos
+// sin version son de la versión 1.
+const CurrentVersion = 2
+
+// Migración de from a from+1 sobre el documento YAML (conserva comentarios y orden).
+// apply indica si cambió el documento: un paso sin cambios no se reporta.
+type migration struct {
+	from        int
+	description string
+	apply       func(doc *yaml.Node) (bool, error)
+}
+
+var migrations = []migration{
+	{1, "move the runtime shared by every function to provider.runtime", migrateSharedRuntime},
+}
+
+// Migrate lleva el YAML a CurrentVersion. Devuelve el documento nuevo y la
+// descripción de cada paso aplicado (vacía si ya estaba al día).
+func Migrate(b []byte) ([]byte, []string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, nil, fmt.Errorf("error parsing YAML: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config must be a YAML mapping")
+	}
+	doc := root.Content[0]
+
+	version := 1
+	if v := mappingValue(doc, "version"); v != nil {
+		if err := v.Decode(&version); err != nil {
+			return nil, nil, fmt.Errorf("version must be an integer: %w", err)
+		}
+	}
+	if version > CurrentVersion {
+		return nil, nil, fmt.Errorf("config version %d is newer than the supported version %d", version, CurrentVersion)
+	}
+
+	if version == CurrentVersion {
+		return b, nil, nil
+	}
+
+	var steps []string
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+		changed, err := m.apply(doc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("migrating from version %d: %w", m.from, err)
+		}
+		if changed {
+			steps = append(steps, fmt.Sprintf("v%d → v%d: %s", m.from, m.from+1, m.description))
+		}
+	}
+
+	setMappingValue(doc, "version", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(CurrentVersion)}, true)
+	steps = append(steps, fmt.Sprintf("set version: %d", CurrentVersion))
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, nil, fmt.Errorf("error encoding config: %w", err)
+	}
+	return out.Bytes(), steps, nil
+}
+
+// ValidateMigrated carga y valida el YAML migrado igual que un archivo normal.
+// Se escribe junto al original para que las rutas relativas resuelvan igual.
+func ValidateMigrated(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".qriosls-migrate-*.yml")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing temporary file: %w", err)
+	}
+	tmp.Close()
+
+	cfg, err := Load(tmp.Name())
+	if err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// v1 → v2: un runtime repetido en todas las funciones pasa a provider.runtime
+func migrateSharedRuntime(doc *yaml.Node) (bool, error) {
+	functions := mappingValue(doc, "functions")
+	if functions == nil || functions.Kind != yaml.MappingNode || len(functions.Content) == 0 {
+		return false, nil
+	}
+
+	shared := ""
+	for i := 1; i < len(functions.Content); i += 2 {
+		runtime := mappingValue(functions.Content[i], "runtime")
+		if runtime == nil || runtime.Value == "" || (shared != "" && runtime.Value != shared) {
+			return false, nil
+		}
+		shared = runtime.Value
+	}
+
+	provider := mappingValue(doc, "provider")
+	if provider == nil {
+		provider = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(doc, "provider", provider, false)
+	}
+	if current := mappingValue(provider, "runtime"); current != nil && current.Value != shared {
+		return false, nil // provider.runtime distinto: las funciones lo reemplazan a propósito
+	}
+	setMappingValue(provider, "runtime", &yaml.Node{Kind: yaml.ScalarNode, Value: shared}, false)
+
+	for i := 1; i < len(functions.Content); i += 2 {
+		deleteMappingKey(functions.Content[i], "runtime")
+	}
+	return true, nil
+}
+
+// mappingValue devuelve el valor de key en un mapping, o nil
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue reemplaza key o la agrega (al principio si first)
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node, first bool) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+	if first {
+		m.Content = append([]*yaml.Node{keyNode, value}, m.Content...)
+		return
+	}
+	m.Content = append(m.Content, keyNode, value)
+}
+
+func deleteMappingKey(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrateReportsOnlyAppliedSteps(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		wantSteps []string
+		wantYAML  []string // fragmentos esperados en el documento migrado
+	}{
+		{
+			name: "shared runtime",
+			doc: `service: orders
+functions:
+  create:
+    runtime: nodejs20.x
+  list:
+    runtime: nodejs20.x
+`,
+			wantSteps: []string{"v1 → v2: move the runtime shared by every function to provider.runtime", "set version: 2"},
+			wantYAML:  []string{"version: 2", "provider:\n  runtime: nodejs20.x"},
+		},
+		{
+			name: "mixed runtimes",
+			doc: `service: orders
+functions:
+  create:
+    runtime: nodejs20.x
+  list:
+    runtime: python3.12
+`,
+			wantSteps: []string{"set version: 2"},
+			wantYAML:  []string{"version: 2", "runtime: nodejs20.x", "runtime: python3.12"},
+		},
+		{
+			name: "provider runtime differs",
+			doc: `service: orders
+provider:
+  runtime: python3.12
+functions:
+  create:
+    runtime: nodejs20.x
+`,
+			wantSteps: []string{"set version: 2"},
+			wantYAML:  []string{"version: 2", "provider:\n  runtime: python3.12", "    runtime: nodejs20.x"},
+		},
+		{
+			name: "already current",
+			doc:  "version: 2\nservice: orders\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, steps, err := Migrate([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(steps, "\n") != strings.Join(tt.wantSteps, "\n") {
+				t.Errorf("steps = %q, want %q", steps, tt.wantSteps)
+			}
+			if tt.wantYAML == nil && string(out) != tt.doc {
+				t.Errorf("an up-to-date config was rewritten:\n%s", out)
+			}
+			for _, want := range tt.wantYAML {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("migrated config lacks %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
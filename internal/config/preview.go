@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Límites de nombres de AWS
+const (
+	maxStackNameLength    = 128
+	maxFunctionNameLength = 64
+	maxPreviewIDLength    = 20
+	maxBucketNameLength   = 63
+)
+
+var rePreviewID = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// StackName devuelve el nombre del stack de CloudFormation: <service>-<stage>,
+// más -<preview> en los despliegues de preview
+func (c *ServerlessConfig) StackName() string {
+	name := c.Service + "-" + c.Stage
+	if c.Preview != "" {
+		name += "-" + c.Preview
+	}
+	return name
+}
+
+// ApplyPreview agrega el sufijo de preview (p. ej. "pr-123") a los nombres de
+// funciones, del API y de los buckets que crea el stack para que varios stacks
+// convivan en la misma cuenta
+func (c *ServerlessConfig) ApplyPreview(id string) error {
+	if id == "" {
+		return nil
+	}
+	if len(id) > maxPreviewIDLength || !rePreviewID.MatchString(id) {
+		return fmt.Errorf("preview id '%s' is invalid. Only alphanumeric and hyphens allowed (max %d characters)", id, maxPreviewIDLength)
+	}
+
+	// Los nombres de bucket son globales y en minúsculas: se valida antes de tocar nada
+	bucketSuffix := "-" + strings.ToLower(id)
+	for _, funcName := range SortedFunctionNames(c.Functions) {
+		for i, e := range c.Functions[funcName].Events {
+			if isStackBucket(e) && len(e.Bucket+bucketSuffix) > maxBucketNameLength {
+				return fmt.Errorf("bucket '%s' in event %d of function '%s' exceeds %d characters with preview suffix '%s'",
+					e.Bucket, i, funcName, maxBucketNameLength, bucketSuffix)
+			}
+		}
+	}
+
+	c.Preview = id
+	if c.Api != nil && c.Api.Name != "" {
+		c.Api.Name += "-" + id
+	}
+	for funcName, function := range c.Functions {
+		function.FunctionName += "-" + id
+		for i, e := range function.Events {
+			if isStackBucket(e) {
+				function.Events[i].Bucket += bucketSuffix
+			}
+		}
+		c.Functions[funcName] = function
+	}
+	return nil
+}
+
+// isStackBucket indica si un evento s3 usa un bucket creado por el stack
+func isStackBucket(e LambdaEvent) bool {
+	return strings.EqualFold(e.Type, "s3") && !e.Existing && e.Bucket != ""
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyPreviewSuffixesStackBuckets(t *testing.T) {
+	cfg := validConfig()
+	f := cfg.Functions["create"]
+	f.Events = []LambdaEvent{
+		{Type: "s3", Bucket: "orders-uploads", Event: "s3:ObjectCreated:*"},
+		{Type: "s3", Bucket: "shared-archive", Event: "s3:ObjectCreated:*", Existing: true},
+	}
+	cfg.Functions["create"] = f
+
+	if err := cfg.ApplyPreview("PR-7"); err != nil {
+		t.Fatal(err)
+	}
+	events := cfg.Functions["create"].Events
+	if got := events[0].Bucket; got != "orders-uploads-pr-7" {
+		t.Errorf("stack bucket = %q, want orders-uploads-pr-7", got)
+	}
+	if got := events[1].Bucket; got != "shared-archive" {
+		t.Errorf("existing bucket = %q, want it unchanged", got)
+	}
+	if got := cfg.Functions["create"].FunctionName; got != "create-order-PR-7" {
+		t.Errorf("functionName = %q, want create-order-PR-7", got)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate after ApplyPreview: %v", err)
+	}
+}
+
+func TestApplyPreviewRejectsLongBucketNames(t *testing.T) {
+	cfg := validConfig()
+	f := cfg.Functions["create"]
+	f.Events = []LambdaEvent{{Type: "s3", Bucket: strings.Repeat("b", 60), Event: "s3:ObjectCreated:*"}}
+	cfg.Functions["create"] = f
+
+	err := cfg.ApplyPreview("pr-123")
+	if err == nil || !strings.Contains(err.Error(), "exceeds 63 characters with preview suffix '-pr-123'") {
+		t.Fatalf("ApplyPreview error = %v, want the bucket length error", err)
+	}
+	if got := cfg.Functions["create"].FunctionName; got != "create-order" {
+		t.Errorf("functionName = %q, a rejected preview must leave the config untouched", got)
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// HttpRequestConfig declara lo que API Gateway exige antes de invocar la
+// función: query strings y headers requeridos y el JSON schema del body
+type HttpRequestConfig struct {
+	QueryStrings []string    `yaml:"querystrings,omitempty"` // Nombres de query strings requeridos
+	Headers      []string    `yaml:"headers,omitempty"`      // Nombres de headers requeridos
+	Schema       interface{} `yaml:"schema,omitempty"`       // Igual que requestSchema: ruta a un archivo o objeto en línea
+}
+
+// Nombres de query strings y headers (token de RFC 7230)
+var reRequestParamName = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// Validate comprueba los nombres de los parámetros requeridos
+func (r *HttpRequestConfig) Validate() error {
+	for _, group := range []struct {
+		field string
+		names []string
+	}{{"querystrings", r.QueryStrings}, {"headers", r.Headers}} {
+		seen := make(map[string]bool)
+		for _, name := range group.names {
+			if !reRequestParamName.MatchString(name) {
+				return fmt.Errorf("request.%s name '%s' is invalid", group.field, name)
+			}
+			if seen[name] {
+				return fmt.Errorf("request.%s '%s' is declared twice", group.field, name)
+			}
+			seen[name] = true
+		}
+	}
+	return nil
+}
+
+// HasRequiredParams indica si el bloque exige algún query string o header
+func (r *HttpRequestConfig) HasRequiredParams() bool {
+	return r != nil && (len(r.QueryStrings) > 0 || len(r.Headers) > 0)
+}
+
+// bodySchema devuelve el schema del body: requestSchema o request.schema
+func (e LambdaEvent) bodySchema() interface{} {
+	if e.RequestSchema != nil {
+		return e.RequestSchema
+	}
+	if e.Request != nil {
+		return e.Request.Schema
+	}
+	return nil
+}
+
+// ImpliedRequestValidation devuelve el modo que activan request y requestSchema
+// cuando ni el evento ni api fijan requestValidation ("" = sin validación)
+func (e LambdaEvent) ImpliedRequestValidation() string {
+	switch {
+	case e.bodySchema() != nil:
+		return RequestValidationAll
+	case e.Request.HasRequiredParams():
+		return RequestValidationParams
+	}
+	return ""
+}
+
+// RequestSchemaJSON devuelve el JSON schema del body de un evento http: el
+// archivo indicado en requestSchema (o request.schema) o el objeto escrito en
+// línea. nil si no hay.
+func (e LambdaEvent) RequestSchemaJSON() ([]byte, error) {
+	var data []byte
+	switch schema := e.bodySchema().(type) {
+	case nil:
+		return nil, nil
+	case string:
+		b, err := os.ReadFile(schema)
+		if err != nil {
+			return nil, fmt.Errorf("error reading requestSchema: %w", err)
+		}
+		if !json.Valid(b) {
+			return nil, fmt.Errorf("requestSchema file '%s' is not valid JSON", schema)
+		}
+		data = b
+	case map[string]interface{}:
+		b, err := json.Marshal(schema)
+		if err != nil {
+			return nil, fmt.Errorf("requestSchema is not representable as JSON: %w", err)
+		}
+		data = b
+	default:
+		return nil, fmt.Errorf("requestSchema must be a file path or an inline JSON schema object")
+	}
+
+	// Forma canónica (claves ordenadas): esquemas iguales dan los mismos bytes
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("requestSchema must be a JSON object: %w", err)
+	}
+	return json.Marshal(obj)
+}
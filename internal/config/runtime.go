@@ -0,0 +1,64 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// Handler por defecto de los runtimes provided: AWS lo ignora y ejecuta el
+// archivo bootstrap de la raíz del asset
+const ProvidedHandler = "bootstrap"
+
+// Alias aceptados (ya normalizados) -> nombre canónico del runtime en AWS Lambda
+var runtimeAliases = map[string]string{
+	"nodejs20.x": "nodejs20.x", "nodejs20x": "nodejs20.x", "nodejs20": "nodejs20.x",
+	"nodejs18.x": "nodejs18.x", "nodejs18x": "nodejs18.x", "nodejs18": "nodejs18.x",
+	"python3.12": "python3.12", "python312": "python3.12",
+	"python3.11": "python3.11", "python311": "python3.11",
+	"python3.10": "python3.10", "python310": "python3.10",
+	"python3.9": "python3.9", "python39": "python3.9",
+	"python3.8": "python3.8", "python38": "python3.8",
+	"java17":  "java17",
+	"dotnet8": "dotnet8", "dotnet8.0": "dotnet8", "dotnet80": "dotnet8", "dotnetcore8": "dotnet8",
+	"ruby3.2": "ruby3.2", "ruby32": "ruby3.2",
+	"provided.al2": "provided.al2", "providedal2": "provided.al2", "provided": "provided.al2",
+	"provided.al2023": "provided.al2023", "providedal2023": "provided.al2023",
+
+	// Go se despliega como runtime custom que ejecuta el binario bootstrap:
+	// go/go1.x usan provided.al2; para Amazon Linux 2023, runtime: provided.al2023
+	"go1.x": "provided.al2", "go1x": "provided.al2", "go": "provided.al2",
+}
+
+// normaliza el nombre para comparar alias ("Node-JS 20.x" -> "nodejs20.x")
+func normalizeRuntimeKey(s string) string {
+	key := strings.ToLower(strings.TrimSpace(s))
+	key = strings.ReplaceAll(key, "_", "")
+	key = strings.ReplaceAll(key, "-", "")
+	key = strings.ReplaceAll(key, " ", "")
+	return key
+}
+
+// CanonicalRuntime devuelve el nombre de AWS Lambda para el runtime
+// configurado, o "" si no está soportado
+func CanonicalRuntime(s string) string {
+	return runtimeAliases[normalizeRuntimeKey(s)]
+}
+
+// SupportedRuntimes devuelve los nombres canónicos soportados, ordenados
+func SupportedRuntimes() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, canonical := range runtimeAliases {
+		if !seen[canonical] {
+			seen[canonical] = true
+			names = append(names, canonical)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsProvidedRuntime indica si el runtime es custom (provided.al2/al2023, incluido Go)
+func IsProvidedRuntime(s string) bool {
+	return strings.HasPrefix(CanonicalRuntime(s), "provided.")
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRuntime(t *testing.T) {
+	tests := []struct {
+		runtime string
+		wantErr bool
+	}{
+		{"nodejs20.x", false},
+		{"NodeJS-20.x", false},
+		{"python3.12", false},
+		{"java17", false},
+		{"go", false},
+		{"node16", true},
+		{"python2.7", true},
+		{"cobol", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.runtime, func(t *testing.T) {
+			cfg := validConfig()
+			f := cfg.Functions["create"]
+			f.Runtime = tt.runtime
+			cfg.Functions["create"] = f
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() with runtime %q = %v, wantErr %v", tt.runtime, err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			msg := err.Error()
+			if !strings.Contains(msg, "runtime '"+tt.runtime+"' is not supported for function 'create'") {
+				t.Errorf("error %q should name the runtime and the function", msg)
+			}
+			for _, supported := range SupportedRuntimes() {
+				if !strings.Contains(msg, supported) {
+					t.Errorf("error %q does not list supported runtime %s", msg, supported)
+				}
+			}
+		})
+	}
+}
+
+func TestCanonicalRuntimeAliases(t *testing.T) {
+	tests := []struct {
+		runtime  string
+		want     string
+		provided bool
+	}{
+		{"go", "provided.al2", true},
+		{"go1.x", "provided.al2", true},
+		{"Go1.X", "provided.al2", true},
+		{"provided", "provided.al2", true},
+		{"provided.al2", "provided.al2", true},
+		{"provided.al2023", "provided.al2023", true},
+		{"provided-al2023", "provided.al2023", true},
+		{"nodejs20", "nodejs20.x", false},
+		{"python312", "python3.12", false},
+		{"go2", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.runtime, func(t *testing.T) {
+			if got := CanonicalRuntime(tt.runtime); got != tt.want {
+				t.Errorf("CanonicalRuntime(%q) = %q, want %q", tt.runtime, got, tt.want)
+			}
+			if got := IsProvidedRuntime(tt.runtime); got != tt.provided {
+				t.Errorf("IsProvidedRuntime(%q) = %v, want %v", tt.runtime, got, tt.provided)
+			}
+		})
+	}
+}
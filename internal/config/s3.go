@@ -0,0 +1,43 @@
+package config
+
+import "regexp"
+
+// Eventos de notificación de S3 -> nombre del EventType de CDK (awss3.EventType)
+var s3EventTypes = map[string]string{
+	"s3:ObjectCreated:*":                               "OBJECT_CREATED",
+	"s3:ObjectCreated:Put":                             "OBJECT_CREATED_PUT",
+	"s3:ObjectCreated:Post":                            "OBJECT_CREATED_POST",
+	"s3:ObjectCreated:Copy":                            "OBJECT_CREATED_COPY",
+	"s3:ObjectCreated:CompleteMultipartUpload":         "OBJECT_CREATED_COMPLETE_MULTIPART_UPLOAD",
+	"s3:ObjectRemoved:*":                               "OBJECT_REMOVED",
+	"s3:ObjectRemoved:Delete":                          "OBJECT_REMOVED_DELETE",
+	"s3:ObjectRemoved:DeleteMarkerCreated":             "OBJECT_REMOVED_DELETE_MARKER_CREATED",
+	"s3:ObjectRestore:*":                               "OBJECT_RESTORE",
+	"s3:ObjectRestore:Post":                            "OBJECT_RESTORE_POST",
+	"s3:ObjectRestore:Completed":                       "OBJECT_RESTORE_COMPLETED",
+	"s3:ObjectRestore:Delete":                          "OBJECT_RESTORE_DELETE",
+	"s3:ReducedRedundancyLostObject":                   "REDUCED_REDUNDANCY_LOST_OBJECT",
+	"s3:Replication:*":                                 "REPLICATION",
+	"s3:Replication:OperationFailedReplication":        "REPLICATION_OPERATION_FAILED_REPLICATION",
+	"s3:Replication:OperationMissedThreshold":          "REPLICATION_OPERATION_MISSED_THRESHOLD",
+	"s3:Replication:OperationReplicatedAfterThreshold": "REPLICATION_OPERATION_REPLICATED_AFTER_THRESHOLD",
+	"s3:Replication:OperationNotTracked":               "REPLICATION_OPERATION_NOT_TRACKED",
+	"s3:LifecycleExpiration:*":                         "LIFECYCLE_EXPIRATION",
+	"s3:LifecycleExpiration:Delete":                    "LIFECYCLE_EXPIRATION_DELETE",
+	"s3:LifecycleExpiration:DeleteMarkerCreated":       "LIFECYCLE_EXPIRATION_DELETE_MARKER_CREATED",
+	"s3:LifecycleTransition":                           "LIFECYCLE_TRANSITION",
+	"s3:IntelligentTiering":                            "INTELLIGENT_TIERING",
+	"s3:ObjectTagging:*":                               "OBJECT_TAGGING",
+	"s3:ObjectTagging:Put":                             "OBJECT_TAGGING_PUT",
+	"s3:ObjectTagging:Delete":                          "OBJECT_TAGGING_DELETE",
+	"s3:ObjectAcl:Put":                                 "OBJECT_ACL_PUT",
+}
+
+// Nombres de bucket válidos (3-63 caracteres, minúsculas, dígitos, puntos y guiones)
+var reBucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// S3EventType devuelve el nombre del awss3.EventType de CDK para un evento de
+// S3 (s3:ObjectCreated:*), o "" si S3 no lo admite
+func S3EventType(event string) string {
+	return s3EventTypes[event]
+}
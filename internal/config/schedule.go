@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rate(<n> <unidad>): singular solo cuando n es 1, como exige EventBridge
+var reRateExpression = regexp.MustCompile(`^rate\((\d+) (minute|minutes|hour|hours|day|days)\)$`)
+
+// Caracteres permitidos por campo de cron(min hora díaMes mes díaSemana año)
+var cronFields = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"minutes", regexp.MustCompile(`^[0-9,\-*/]+$`)},
+	{"hours", regexp.MustCompile(`^[0-9,\-*/]+$`)},
+	{"day-of-month", regexp.MustCompile(`^([0-9,\-*?/LW]+)$`)},
+	{"month", regexp.MustCompile(`^([0-9,\-*/]|JAN|FEB|MAR|APR|MAY|JUN|JUL|AUG|SEP|OCT|NOV|DEC)+$`)},
+	{"day-of-week", regexp.MustCompile(`^([0-9,\-*?L#]|SUN|MON|TUE|WED|THU|FRI|SAT)+$`)},
+	{"year", regexp.MustCompile(`^[0-9,\-*/]+$`)},
+}
+
+// validateScheduleExpression comprueba la sintaxis de rate(...) y cron(...) antes
+// de sintetizar, para que `qriosls validate` detecte los errores tipográficos
+func validateScheduleExpression(expr string) error {
+	switch {
+	case strings.HasPrefix(expr, "rate("):
+		m := reRateExpression.FindStringSubmatch(expr)
+		if m == nil {
+			return fmt.Errorf("expected rate(<value> minute(s)|hour(s)|day(s))")
+		}
+		value, err := strconv.Atoi(m[1])
+		if err != nil || value < 1 {
+			return fmt.Errorf("rate value must be a positive integer")
+		}
+		if singular := !strings.HasSuffix(m[2], "s"); singular != (value == 1) {
+			return fmt.Errorf("rate unit must be singular only for a value of 1 (e.g. rate(1 hour), rate(5 hours))")
+		}
+		return nil
+
+	case strings.HasPrefix(expr, "cron(") && strings.HasSuffix(expr, ")"):
+		fields := strings.Fields(expr[len("cron(") : len(expr)-1])
+		if len(fields) != len(cronFields) {
+			return fmt.Errorf("cron needs 6 fields (minutes hours day-of-month month day-of-week year), got %d", len(fields))
+		}
+		for i, field := range fields {
+			if !cronFields[i].pattern.MatchString(strings.ToUpper(field)) {
+				return fmt.Errorf("cron %s field '%s' is invalid", cronFields[i].name, field)
+			}
+		}
+		// EventBridge exige '?' en exactamente uno de día del mes / día de la semana
+		if (fields[2] == "?") == (fields[4] == "?") {
+			return fmt.Errorf("cron requires '?' in exactly one of day-of-month and day-of-week")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("must be rate(...) or cron(...)")
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Memoria con unidad: "512", "512MB", "512M", "1GB", "1.5G"
+var reMemory = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(MB|M|MIB|GB|G|GIB)?$`)
+
+// normalizeUnits convierte timeout ("30s", "2m") y memorySize ("512MB", "1GB")
+// de provider y de cada función a los enteros en segundos y MB que esperan
+// ProviderConfig y LambdaFunc. Los enteros se dejan tal cual.
+func normalizeUnits(doc map[string]interface{}) error {
+	if provider, ok := doc["provider"].(map[string]interface{}); ok {
+		if err := normalizeUnitFields(provider); err != nil {
+			return fmt.Errorf("provider: %w", err)
+		}
+	}
+
+	functions, _ := doc["functions"].(map[string]interface{})
+	for funcName, fn := range functions {
+		fnMap, ok := fn.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := normalizeUnitFields(fnMap); err != nil {
+			return fmt.Errorf("function '%s': %w", funcName, err)
+		}
+	}
+	return nil
+}
+
+func normalizeUnitFields(m map[string]interface{}) error {
+	if v, ok := m["timeout"].(string); ok {
+		seconds, err := parseSeconds(v)
+		if err != nil {
+			return err
+		}
+		m["timeout"] = seconds
+	}
+	if v, ok := m["memorySize"].(string); ok {
+		mb, err := parseMegabytes(v)
+		if err != nil {
+			return err
+		}
+		m["memorySize"] = mb
+	}
+	return nil
+}
+
+// parseSeconds acepta segundos sin unidad o una duración de Go ("30s", "2m", "1m30s")
+func parseSeconds(v string) (int, error) {
+	v = strings.TrimSpace(v)
+	if n, err := strconv.Atoi(v); err == nil {
+		return n, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d%time.Second != 0 {
+		return 0, fmt.Errorf("timeout '%s' is not a valid whole-second duration (e.g. 30, 30s, 2m)", v)
+	}
+	return int(d / time.Second), nil
+}
+
+// parseMegabytes acepta MB sin unidad o con sufijo MB/GB (1GB = 1024MB)
+func parseMegabytes(v string) (int, error) {
+	m := reMemory.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(v)))
+	if m == nil {
+		return 0, fmt.Errorf("memorySize '%s' is not a valid size (e.g. 512, 512MB, 1GB)", v)
+	}
+
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("memorySize '%s' is not a valid size (e.g. 512, 512MB, 1GB)", v)
+	}
+	switch m[2] {
+	case "GB", "G", "GIB":
+		n *= 1024
+	}
+
+	if n != float64(int(n)) {
+		return 0, fmt.Errorf("memorySize '%s' is not a whole number of MB", v)
+	}
+	return int(n), nil
+}
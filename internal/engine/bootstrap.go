@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+	"github.com/qrioso-software/qriososls/internal/util"
+)
+
+// ErrBootstrapMissing se devuelve cuando el code de un runtime provided no
+// contiene el ejecutable bootstrap (Runtime.InvalidEntrypoint en AWS)
+var ErrBootstrapMissing = errors.New("bootstrap not found")
+
+// CheckBootstraps verifica que cada función con runtime provided tenga un
+// archivo bootstrap en la raíz de su code ya compilado.
+// Los .zip (code o artifact) se omiten.
+func CheckBootstraps(cfg *config.ServerlessConfig) error {
+	active := cfg.ActiveFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		fn := active[funcName]
+		if !config.IsProvidedRuntime(fn.Runtime) {
+			continue
+		}
+
+		codePath := util.ResolveVars(fn.AssetPath(), cfg.Stage)
+		if strings.EqualFold(filepath.Ext(codePath), ".zip") {
+			continue
+		}
+
+		bootstrap := filepath.Join(codePath, config.ProvidedHandler)
+		if info, err := os.Stat(bootstrap); err != nil || info.IsDir() {
+			return fmt.Errorf("%w: function '%s' (runtime %s) needs %s; build the function before synth",
+				ErrBootstrapMissing, funcName, config.CanonicalRuntime(fn.Runtime), bootstrap)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+func TestCheckBootstraps(t *testing.T) {
+	withBootstrap := t.TempDir()
+	if err := os.WriteFile(filepath.Join(withBootstrap, "bootstrap"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	bootstrapDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(bootstrapDir, "bootstrap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	empty := t.TempDir()
+
+	tests := []struct {
+		name    string
+		fn      config.LambdaFunc
+		wantErr bool
+	}{
+		{name: "provided with bootstrap", fn: config.LambdaFunc{Runtime: "provided.al2023", Code: withBootstrap}},
+		{name: "go with bootstrap", fn: config.LambdaFunc{Runtime: "go", Code: withBootstrap}},
+		{name: "provided without bootstrap", fn: config.LambdaFunc{Runtime: "provided.al2", Code: empty}, wantErr: true},
+		{name: "go not built yet", fn: config.LambdaFunc{Runtime: "go1.x", Code: empty}, wantErr: true},
+		{name: "bootstrap is a directory", fn: config.LambdaFunc{Runtime: "provided.al2", Code: bootstrapDir}, wantErr: true},
+		{name: "prebuilt zip", fn: config.LambdaFunc{Runtime: "provided.al2", Artifact: "dist/api.zip"}},
+		{name: "managed runtime", fn: config.LambdaFunc{Runtime: "nodejs20.x", Code: empty}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn.FunctionName = "api"
+			cfg := &config.ServerlessConfig{
+				Service:   "svc",
+				Stage:     "dev",
+				Functions: map[string]config.LambdaFunc{"api": tt.fn},
+			}
+			err := CheckBootstraps(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckBootstraps = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrBootstrapMissing) {
+				t.Errorf("error %v is not ErrBootstrapMissing", err)
+			}
+		})
+	}
+}
+
+// Synth falla con ErrBootstrapMissing antes de construir el stack
+func TestSynthMissingBootstrap(t *testing.T) {
+	cfg := runtimeConfig("provided.al2023")
+	fn := cfg.Functions["worker"]
+	fn.Code = t.TempDir()
+	cfg.Functions["worker"] = fn
+
+	if _, err := Synth(cfg, t.TempDir()); !errors.Is(err, ErrBootstrapMissing) {
+		t.Fatalf("Synth = %v, want ErrBootstrapMissing", err)
+	}
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsapigateway"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// Opciones de preflight por path: el cors del evento reemplaza al del API solo
+// para su recurso. Los paths con un OPTIONS propio no llevan preflight generado.
+func corsByPath(cfg *config.ServerlessConfig) map[string]*config.CorsConfig {
+	var defaults *config.CorsConfig
+	if cfg.Api != nil {
+		defaults = cfg.Api.Cors
+	}
+	explicit := cfg.ExplicitOptionsPaths()
+
+	out := make(map[string]*config.CorsConfig)
+	active := cfg.ActiveFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		for _, ev := range active[funcName].Events {
+			if strings.ToUpper(ev.Type) != "HTTP" {
+				continue
+			}
+			path := joinPath(ev.Resource, ev.Path)
+			if explicit[path] {
+				continue
+			}
+			switch {
+			case ev.Cors != nil:
+				out[path] = ev.Cors
+			case defaults != nil && out[path] == nil:
+				out[path] = defaults
+			}
+		}
+	}
+	return out
+}
+
+// Agrega el preflight OPTIONS a cada recurso según corsByPath
+func addCorsPreflights(cfg *config.ServerlessConfig, resources map[string]awsapigateway.IResource) {
+	byPath := corsByPath(cfg)
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		res, ok := resources[norm(path)]
+		if !ok {
+			continue
+		}
+		res.AddCorsPreflight(corsOptions(byPath[path]))
+	}
+}
+
+func corsOptions(c *config.CorsConfig) *awsapigateway.CorsOptions {
+	opts := &awsapigateway.CorsOptions{
+		AllowOrigins:     jsii.Strings(c.AllowOrigins...),
+		AllowMethods:     awsapigateway.Cors_ALL_METHODS(),
+		AllowCredentials: jsii.Bool(c.AllowCredentials),
+	}
+	if len(c.AllowMethods) > 0 {
+		opts.AllowMethods = jsii.Strings(c.AllowMethods...)
+	}
+	if len(c.AllowHeaders) > 0 {
+		opts.AllowHeaders = jsii.Strings(c.AllowHeaders...)
+	}
+	return opts
+}
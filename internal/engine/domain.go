@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"log"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsapigateway"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awscertificatemanager"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// Output con el host al que debe apuntar el DNS del dominio propio
+const OutputDomainTarget = "ApiDomainTarget"
+
+// addDomain crea el dominio propio (regional) y lo mapea al stage del API.
+// Solo en el stack desplegado: SAM no sirve dominios y el local no tiene certificado.
+func addDomain(scope constructs.Construct, api awsapigateway.RestApi, domain *config.DomainConfig) {
+	if domain == nil {
+		return
+	}
+
+	dn := awsapigateway.NewDomainName(scope, jsii.String("ApiDomain"), &awsapigateway.DomainNameProps{
+		DomainName: jsii.String(domain.DomainName),
+		Certificate: awscertificatemanager.Certificate_FromCertificateArn(scope,
+			jsii.String("ApiDomainCertificate"), jsii.String(domain.CertificateArn)),
+		EndpointType: awsapigateway.EndpointType_REGIONAL,
+	})
+
+	opts := &awsapigateway.BasePathMappingOptions{}
+	if domain.BasePath != "" {
+		opts.BasePath = jsii.String(domain.BasePath)
+	}
+	dn.AddBasePathMapping(api, opts)
+
+	awscdk.NewCfnOutput(scope, jsii.String(OutputDomainTarget), &awscdk.CfnOutputProps{
+		Value:       dn.DomainNameAliasDomainName(),
+		Description: jsii.String("DNS target (CNAME or alias) for " + domain.DomainName),
+	})
+
+	log.Printf("🌐 Custom domain https://%s/%s → API", domain.DomainName, domain.BasePath)
+}
@@ -2,8 +2,8 @@ package engine
 
 import (
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -19,24 +19,17 @@ import (
 )
 
 func norm(p string) string {
-	s := "/" + strings.Trim(strings.ReplaceAll(p, "\\", "/"), "/")
-	s = strings.ReplaceAll(s, "//", "/")
-	return s
+	return util.NormAPIPath(p)
 }
 
 // concatena resource + path manejando "/", "", etc.
 func joinPath(resource, path string) string {
-	r := strings.TrimSpace(resource)
-	p := strings.TrimSpace(path)
-
-	switch {
-	case r == "" || r == "/":
-		return norm(p)
-	case p == "" || p == "/":
-		return norm(r)
-	default:
-		return norm(r + "/" + strings.TrimPrefix(p, "/"))
-	}
+	return util.JoinAPIPath(resource, path)
+}
+
+// RoutePath devuelve la ruta absoluta de API Gateway para un evento HTTP
+func RoutePath(ev config.LambdaEvent) string {
+	return joinPath(ev.Resource, ev.Path)
 }
 
 // Crea (o reutiliza) toda la cadena de recursos desde root: "/a/b/{id}/c"
@@ -90,195 +83,175 @@ func extractPathParams(p string) []string {
 	return out
 }
 
-// Construye el map correcto para REST v1: map[string]*bool
-func requiredPathParamsMap(params []string) *map[string]*bool {
-	if len(params) == 0 {
-		return nil
-	}
-	m := make(map[string]*bool, len(params))
-	for _, name := range params {
+// Construye el map correcto para REST v1 (map[string]*bool): los {param} del
+// path más los query strings y headers requeridos por request; nil si no hay ninguno
+func requestParamsMap(pathParams []string, req *config.HttpRequestConfig) *map[string]*bool {
+	m := make(map[string]*bool)
+	for _, name := range pathParams {
 		// clave: "method.request.path.<param>"
 		m["method.request.path."+name] = jsii.Bool(true)
 	}
+	if req != nil {
+		for _, name := range req.QueryStrings {
+			m["method.request.querystring."+name] = jsii.Bool(true)
+		}
+		for _, name := range req.Headers {
+			m["method.request.header."+name] = jsii.Bool(true)
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
 	return &m
 }
 
-func NewStack(scope constructs.Construct, id string, cfg *config.ServerlessConfig, env *awscdk.Environment) awscdk.Stack {
-	stack := awscdk.NewStack(scope, &id, &awscdk.StackProps{Env: env})
-
-	// === 1) Resolver API: importar si existe, crear si no
-	var api awsapigateway.IRestApi
-	// if cfg.Api != nil && cfg.Api.Id != "" {
-	// 	// Para poder agregar rutas a un API importado, necesitas también el rootResourceId
-	// 	if cfg.Api.RootResourceId == "" {
-	// 		panic("api.rootResourceId es requerido cuando api.id está definido (necesario para api.Root())")
-	// 	}
-	// 	api = awsapigateway.RestApi_FromRestApiAttributes(
-	// 		stack,
-	// 		jsii.String(fmt.Sprintf("%s-imported-api", cfg.Service)),
-	// 		&awsapigateway.RestApiAttributes{
-	// 			RestApiId:      jsii.String(cfg.Api.Id),
-	// 			RootResourceId: jsii.String(cfg.Api.RootResourceId),
-	// 		},
-	// 	)
-	// } else {
-
-	// }
-
-	apiName := cfg.Service + "-api"
-	if cfg.Api != nil && cfg.Api.Name != "" {
-		apiName = cfg.Api.Name
+// Clave del asset para un code path: funciones que comparten code comparten asset
+func assetKey(codePath string) string {
+	return filepath.ToSlash(filepath.Clean(codePath))
+}
+
+// LocalAssetHash es el hash custom del asset de una función en el stack local.
+// CDK nombra el directorio como cdk.out/asset.<sha256(hash)>.
+func LocalAssetHash(codePath string) string {
+	return assetKey(codePath)
+}
+
+// Reutiliza el mismo AssetCode para el mismo code path, así se sube una sola vez
+func assetFor(cache map[string]awslambda.AssetCode, codePath string, opts *awss3assets.AssetOptions) awslambda.AssetCode {
+	key := assetKey(codePath)
+	if code, ok := cache[key]; ok {
+		return code
 	}
-	api = awsapigateway.NewRestApi(
-		stack,
-		jsii.String(apiName),
-		&awsapigateway.RestApiProps{
-			DeployOptions: &awsapigateway.StageOptions{
-				StageName: jsii.String(cfg.Stage),
-			},
-		},
-	)
-
-	// === 2) Lambdas y eventos
-	for logicalName, fn := range cfg.Functions {
-		functionName := util.ResolveVars(fn.FunctionName, cfg.Stage)
-		codePath := util.ResolveVars(fn.Code, cfg.Stage)
-		logicalName = strings.ReplaceAll(logicalName, "-", "")
-		runtime := toLambdaRuntime(fn.Runtime)
-		if runtime == nil {
-			log.Printf("⚠️ No se encontró un runtime para %s", fn.Runtime)
-			continue
-		}
-		lambdaFn := awslambda.NewFunction(stack, jsii.String(logicalName), &awslambda.FunctionProps{
-			FunctionName: jsii.String(functionName),
-			Runtime:      runtime,
-			Handler:      jsii.String(fn.Handler),
-			Code:         awslambda.AssetCode_FromAsset(jsii.String(codePath), nil),
-			MemorySize:   jsii.Number(float64(fn.MemorySize)),
-			Timeout:      awscdk.Duration_Seconds(jsii.Number(float64(fn.Timeout))),
-		})
+	code := awslambda.AssetCode_FromAsset(jsii.String(codePath), opts)
+	cache[key] = code
+	return code
+}
 
-		for _, ev := range fn.Events {
-			if strings.ToUpper(ev.Type) != "HTTP" {
-				continue
-			}
-
-			// Construir ruta completa: resource + path
-			fullPath := ev.Resource
-			if ev.Path != "" && ev.Path != "/" {
-				fullPath = strings.TrimRight(ev.Resource, "/") + ev.Path
-			}
-
-			if lambdaFn == nil {
-				log.Fatalf("Lambda %s no tiene referencia a Function en stage %s", fn.FunctionName, cfg.Stage)
-			}
-			log.Println(fullPath)
-			log.Println(ev.Method)
-			// Usar addResourceByPath para crear o reutilizar
-			res := addResourceByPath(api, fullPath)
-
-			res.AddMethod(
-				jsii.String(strings.ToUpper(ev.Method)),
-				awsapigateway.NewLambdaIntegration(lambdaFn, nil),
-				nil,
-			)
-		}
+// stackDescription devuelve la descripción del stack, o nil para omitirla
+func stackDescription(cfg *config.ServerlessConfig) *string {
+	if cfg.Description == "" {
+		return nil
+	}
+	return jsii.String(cfg.Description)
+}
 
+// restApiProps devuelve las props comunes del API. Desplegado: api.name (con
+// el sufijo de --preview) o <stack>-api y el stage del config. En local se
+// mantiene <service>-local-api/local y no va la resource policy: SAM no la
+// evalúa, igual que el dominio
+func restApiProps(cfg *config.ServerlessConfig, local bool) *awsapigateway.RestApiProps {
+	if local {
+		return &awsapigateway.RestApiProps{
+			RestApiName:   jsii.String(cfg.Service + "-local-api"),
+			DeployOptions: &awsapigateway.StageOptions{StageName: jsii.String("local")},
+		}
 	}
 
-	return stack
+	name := cfg.StackName() + "-api"
+	props := &awsapigateway.RestApiProps{
+		DeployOptions: &awsapigateway.StageOptions{StageName: jsii.String(cfg.Stage)},
+	}
+	if cfg.Api != nil {
+		if cfg.Api.Name != "" {
+			name = cfg.Api.Name
+		}
+		props.Policy = resourcePolicy(cfg.Api.ResourcePolicy)
+	}
+	props.RestApiName = jsii.String(name)
+	return props
 }
 
-func NewLocalDevStack(scope constructs.Construct, id string, cfg *config.ServerlessConfig, env *awscdk.Environment) constructs.Construct {
-	api := awsapigateway.NewRestApi(scope, jsii.String(cfg.Service+"-local-api"), &awsapigateway.RestApiProps{
-		RestApiName: jsii.String(cfg.Service + "-local-api"),
-		DeployOptions: &awsapigateway.StageOptions{
-			StageName: jsii.String("local"),
-		},
-	})
+// newDevApi crea el API y las funciones del stack (el que se despliega y el
+// que sirve SAM en local) y devuelve el API. Solo lo llama synth, después de
+// CheckRuntimes: cada función tiene un runtime con mapeo a CDK
+func newDevApi(scope constructs.Construct, cfg *config.ServerlessConfig, local bool) awsapigateway.RestApi {
+	// El id del construct no cambia con el nombre: cambiarlo reemplazaría el API desplegado
+	api := awsapigateway.NewRestApi(scope, jsii.String(cfg.Service+"-local-api"), restApiProps(cfg, local))
+	addGatewayResponses(api, cfg.Api)
+	addApiUrlOutput(scope, api)
 
 	// Cache de recursos creados para reutilizarlos entre rutas
 	resources := make(map[string]awsapigateway.IResource)
 	resources["/"] = api.Root()
+	validators := newRequestValidators(scope, api, cfg.Api)
 
-	for logicalName, fn := range cfg.Functions {
+	assets := make(map[string]awslambda.AssetCode)
+	active := cfg.ActiveFunctions()
+	for _, logicalName := range config.SortedFunctionNames(active) {
+		fn := active[logicalName]
 		functionName := util.ResolveVars(fn.FunctionName, cfg.Stage)
-		codePath := util.ResolveVars(fn.Code, cfg.Stage)
+		codePath := cfg.FunctionAssetPath(logicalName)
 		logicalName = strings.ReplaceAll(logicalName, "-", "")
-		runtime := toLambdaRuntime(fn.Runtime)
-
-		if runtime == nil {
-			log.Printf("⚠️ No se encontró un runtime para %s", fn.Runtime)
-			continue
-		}
+		runtime := toLambdaRuntime(fn.Runtime) // synth ya pasó por CheckRuntimes
 
-		lambdaFn := awslambda.NewFunction(scope, jsii.String(logicalName), &awslambda.FunctionProps{
-			FunctionName: jsii.String(functionName),
-			Runtime:      runtime,
-			Handler:      jsii.String(fn.Handler),
-			Code: awslambda.Code_FromAsset(jsii.String(codePath), &awss3assets.AssetOptions{
-				AssetHashType: awscdk.AssetHashType_CUSTOM,
-				AssetHash:     jsii.String(functionName),
-			}),
-			MemorySize: jsii.Number(float64(fn.MemorySize)),
-			Timeout:    awscdk.Duration_Seconds(jsii.Number(float64(fn.Timeout))),
+		code := assetFor(assets, codePath, &awss3assets.AssetOptions{
+			AssetHashType: awscdk.AssetHashType_CUSTOM,
+			AssetHash:     jsii.String(LocalAssetHash(codePath)),
 		})
+		lambdaFn := awslambda.NewFunction(scope, jsii.String(logicalName),
+			functionProps(scope, logicalName, fn, functionName, runtime, code))
+		applyVpc(lambdaFn, fn.Vpc)
+		applyDestinations(scope, logicalName, lambdaFn, fn.Destinations)
+		applyIamRoleStatements(lambdaFn, fn.IamRoleStatements)
+		addFunctionArnOutput(scope, logicalName, lambdaFn, functionName)
 
 		cfn := lambdaFn.Node().DefaultChild().(awscdk.CfnResource)
 		cfn.OverrideLogicalId(jsii.String(functionName))
 
-		for _, ev := range fn.Events {
-			if strings.ToUpper(ev.Type) != "HTTP" {
-				log.Println("Skipping non-HTTP event", ev)
-				continue
-			}
-
-			// Ruta final (abs) => ej: "/bookings/{bookingId}/end"
-			fullPath := joinPath(ev.Resource, ev.Path)
-
-			// Crea/Reutiliza la cadena de recursos
-			finalRes := ensureResourceChain(api, resources, fullPath)
-
-			// Path params requeridos (REST v1)
-			params := extractPathParams(fullPath)
-			reqParams := requiredPathParamsMap(params)
-
-			finalRes.AddMethod(
-				jsii.String(ev.Method),
-				awsapigateway.NewLambdaIntegration(lambdaFn, nil),
-				&awsapigateway.MethodOptions{
-					// AuthorizationType: awsapigateway.AuthorizationType_COGNITO,
-					// Authorizer:        authorizer,
-					RequestParameters: reqParams, // solo si hay {param}
-				},
-			)
-		}
+		addEvents(scope, logicalName, lambdaFn, fn, api, resources, validators)
 	}
+	addCorsPreflights(cfg, resources)
 
-	return scope
+	return api
 }
 
-func addResourceByPath(api awsapigateway.IRestApi, resourcePath string) awsapigateway.IResource {
-	curr := api.Root()
-	p := strings.Trim(resourcePath, "/")
-	if p == "" {
-		return curr
-	}
-	for _, seg := range strings.Split(p, "/") {
-		if seg == "" {
-			continue
+// SynthResult describe lo que generó Synth, para no adivinar rutas en cdk.out
+type SynthResult struct {
+	OutDir       string // Directorio de la cloud assembly
+	StackName    string // Nombre del stack de CloudFormation
+	TemplatePath string // Ruta del template del stack dentro de OutDir
+}
+
+// Synth sintetiza el stack a desplegar en outdir. jsii usa un único proceso Node.js y
+// serializa las llamadas, así que varios Synth no se aceleran en paralelo:
+// lo paralelizable es compilar los assets antes (ver local.buildAllFunctions).
+func Synth(cfg *config.ServerlessConfig, outdir string) (*SynthResult, error) {
+	return synth(cfg, outdir, false)
+}
+
+// SynthLocal sintetiza el stack que sirve SAM en local: igual que Synth pero sin
+// lo que solo existe en AWS (el dominio propio del API)
+func SynthLocal(cfg *config.ServerlessConfig, outdir string) (*SynthResult, error) {
+	return synth(cfg, outdir, true)
+}
+
+func synth(cfg *config.ServerlessConfig, outdir string, local bool) (result *SynthResult, err error) {
+	// jsii reporta los errores de CDK como panics, también los de CheckRuntimes
+	// si el proceso de Node.js murió (p. ej. en un reload de local)
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("synthesis failed: %v", r)
 		}
-		curr = curr.AddResource(jsii.String(seg), nil)
+	}()
+
+	if err := CheckBootstraps(cfg); err != nil {
+		return nil, err
+	}
+	if err := CheckJsiiRuntime(); err != nil {
+		return nil, err
+	}
+	if err := CheckRuntimes(cfg); err != nil {
+		return nil, err
 	}
-	return curr
-}
 
-func Synth(cfg *config.ServerlessConfig, outdir string) error {
+	if outdir == "" {
+		outdir = "cdk.out"
+	}
 
 	app := awscdk.NewApp(&awscdk.AppProps{
 		AutoSynth:               jsii.Bool(true),
 		DefaultStackSynthesizer: awscdk.NewLegacyStackSynthesizer(),
-		Outdir:                  jsii.String("cdk.out"),
+		Outdir:                  jsii.String(outdir),
 	})
 
 	var stackEnv *awscdk.Environment
@@ -291,17 +264,29 @@ func Synth(cfg *config.ServerlessConfig, outdir string) error {
 		}
 	}
 
-	stack := awscdk.NewStack(app, jsii.String(cfg.Service+"-"+cfg.Stage), &awscdk.StackProps{
-		Env: stackEnv,
+	stackName := cfg.StackName()
+	stack := awscdk.NewStack(app, jsii.String(stackName), &awscdk.StackProps{
+		Env:         stackEnv,
+		Description: stackDescription(cfg),
 	})
 
-	NewLocalDevStack(stack, cfg.Service+"-"+cfg.Stage, cfg, stackEnv)
+	api := newDevApi(stack, cfg, local)
+	if !local && cfg.Api != nil {
+		addDomain(stack, api, cfg.Api.Domain)
+	}
+	addProvenance(stack, cfg)
+	runStackHooks(stack, cfg)
 
-	app.Synth(nil)
+	assembly := app.Synth(nil)
+	templatePath := *assembly.GetStackByName(jsii.String(stackName)).TemplateFullPath()
 
 	// sanity check
-	if _, err := os.Stat(outdir); err != nil {
-		return fmt.Errorf("no se encontró %s después de synth: %w", outdir, err)
+	if _, err := os.Stat(templatePath); err != nil {
+		return nil, fmt.Errorf("no se encontró %s después de synth: %w", templatePath, err)
 	}
-	return nil
+	return &SynthResult{
+		OutDir:       *assembly.Directory(),
+		StackName:    stackName,
+		TemplatePath: templatePath,
+	}, nil
 }
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// apiPaths reconstruye las rutas completas de los AWS::ApiGateway::Resource del template
+func apiPaths(resources map[string]map[string]interface{}) []string {
+	var paths []string
+	var pathOf func(id string) string
+	pathOf = func(id string) string {
+		props := resources[id]["Properties"].(map[string]interface{})
+		part := props["PathPart"].(string)
+		if parent, ok := props["ParentId"].(map[string]interface{})["Ref"].(string); ok && resources[parent]["Type"] == "AWS::ApiGateway::Resource" {
+			return pathOf(parent) + "/" + part
+		}
+		return "/" + part
+	}
+	for _, id := range resourcesOfType(resources, "AWS::ApiGateway::Resource") {
+		paths = append(paths, pathOf(id))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Synth y SynthLocal construyen las rutas con el mismo joinPath: lo que
+// responde en local no da 404 desplegado
+func TestSynthAndSynthLocalServeTheSameRoutes(t *testing.T) {
+	cfg := &config.ServerlessConfig{
+		Service: "svc",
+		Stage:   "dev",
+		Functions: map[string]config.LambdaFunc{
+			"users": {
+				FunctionName: "users",
+				Runtime:      "nodejs20.x",
+				Handler:      "index.handler",
+				Code:         t.TempDir(),
+				Events: []config.LambdaEvent{
+					{Type: "http", Resource: "/api/", Path: "/users", Method: "get"},
+					{Type: "http", Resource: "api", Path: "users//{id}", Method: "get"},
+					{Type: "http", Resource: "/", Path: "/health/", Method: "get"},
+				},
+			},
+		},
+	}
+
+	want := "/api /api/users /api/users/{id} /health"
+	deployed := strings.Join(apiPaths(synthTemplate(t, cfg, false)), " ")
+	local := strings.Join(apiPaths(synthTemplate(t, cfg, true)), " ")
+	if deployed != want {
+		t.Errorf("deployed routes = %s, want %s", deployed, want)
+	}
+	if local != deployed {
+		t.Errorf("local routes = %s, deployed routes = %s", local, deployed)
+	}
+}
+
+// Dos synth de la misma configuración producen el mismo template byte a byte
+func TestSynthIsReproducible(t *testing.T) {
+	code := t.TempDir()
+	cfg := &config.ServerlessConfig{
+		Service:   "svc",
+		Stage:     "dev",
+		Functions: map[string]config.LambdaFunc{},
+	}
+	for _, name := range []string{"orders", "users", "billing", "auth", "reports", "search"} {
+		cfg.Functions[name] = config.LambdaFunc{
+			FunctionName: name,
+			Runtime:      "nodejs20.x",
+			Handler:      "index.handler",
+			Code:         code,
+			Events: []config.LambdaEvent{
+				{Type: "http", Resource: "/" + name, Path: "/{id}", Method: "get"},
+				{Type: "schedule", Schedule: "rate(1 hour)"},
+			},
+		}
+	}
+
+	var templates [][]byte
+	for i := 0; i < 2; i++ {
+		result, err := Synth(cfg, t.TempDir())
+		if err != nil {
+			t.Fatalf("Synth: %v", err)
+		}
+		data, err := os.ReadFile(result.TemplatePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		templates = append(templates, data)
+	}
+	if !bytes.Equal(templates[0], templates[1]) {
+		t.Errorf("two synths of the same config produced different templates")
+	}
+}
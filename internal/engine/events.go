@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsapigateway"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsevents"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awseventstargets"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambdaeventsources"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3notifications"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssqs"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// addEvents conecta cada evento de la función a la única Lambda ya creada:
+// HTTP agrega una ruta al API, schedule una regla de EventBridge, sqs un
+// event source mapping y s3 una notificación del bucket. Así una función puede mezclar tipos de trigger.
+func addEvents(scope constructs.Construct, logicalName string, lambdaFn awslambda.Function, fn config.LambdaFunc,
+	api awsapigateway.IRestApi, resources map[string]awsapigateway.IResource, validators *requestValidators) {
+	for i, ev := range fn.Events {
+		switch strings.ToLower(ev.Type) {
+		case "http":
+			// Misma ruta en Synth y SynthLocal: lo que funciona en local funciona desplegado
+			fullPath := joinPath(ev.Resource, ev.Path)
+			res := ensureResourceChain(api, resources, fullPath)
+
+			res.AddMethod(
+				jsii.String(strings.ToUpper(ev.Method)),
+				awsapigateway.NewLambdaIntegration(lambdaFn, nil),
+				&awsapigateway.MethodOptions{
+					RequestParameters: requestParamsMap(extractPathParams(fullPath), ev.Request), // solo si hay {param} o request
+					RequestValidator:  validators.forEvent(ev),
+					RequestModels:     validators.requestModels(ev),
+				},
+			)
+
+		case "schedule":
+			ruleID := fmt.Sprintf("%sSchedule%d", logicalName, i)
+			enabled := ev.Enabled == nil || *ev.Enabled
+			awsevents.NewRule(scope, jsii.String(ruleID), &awsevents.RuleProps{
+				Schedule: awsevents.Schedule_Expression(jsii.String(ev.Schedule)),
+				Enabled:  jsii.Bool(enabled),
+				Targets:  &[]awsevents.IRuleTarget{awseventstargets.NewLambdaFunction(lambdaFn, nil)},
+			})
+			state := "enabled"
+			if !enabled {
+				state = "disabled"
+			}
+			log.Printf("⏰ Schedule rule %s → %s: %s (%s)", ruleID, logicalName, ev.Schedule, state)
+
+		case "sqs":
+			// NewSqsEventSource concede al rol creado por CDK el consumo de la cola
+			lambdaFn.AddEventSource(awslambdaeventsources.NewSqsEventSource(sqsQueue(scope, fmt.Sprintf("%sQueue%d", logicalName, i), ev), sqsProps(ev)))
+
+		case "s3":
+			var filters []*awss3.NotificationKeyFilter
+			if ev.Prefix != "" || ev.Suffix != "" {
+				filters = append(filters, &awss3.NotificationKeyFilter{
+					Prefix: jsii.String(ev.Prefix),
+					Suffix: jsii.String(ev.Suffix),
+				})
+			}
+			s3Bucket(scope, ev).AddEventNotification(awss3.EventType(config.S3EventType(ev.Event)),
+				awss3notifications.NewLambdaDestination(lambdaFn), filters...)
+			log.Printf("🪣 %s subscribes to s3://%s/%s*%s (%s)", logicalName, ev.Bucket, ev.Prefix, ev.Suffix, ev.Event)
+
+		default:
+			log.Printf("⚠️ Skipping unsupported event type '%s' in function %s", ev.Type, logicalName)
+		}
+	}
+}
+
+// Bucket de un evento s3: importado si existing, si no creado por el stack.
+// Los eventos del mismo bucket comparten el construct (lo busca por id en el scope).
+func s3Bucket(scope constructs.Construct, ev config.LambdaEvent) awss3.IBucket {
+	id := "Bucket" + strings.NewReplacer(".", "", "-", "").Replace(ev.Bucket)
+	if child := scope.Node().TryFindChild(jsii.String(id)); child != nil {
+		return child.(awss3.IBucket)
+	}
+	if ev.Existing {
+		return awss3.Bucket_FromBucketName(scope, jsii.String(id), jsii.String(ev.Bucket))
+	}
+	return awss3.NewBucket(scope, jsii.String(id), &awss3.BucketProps{
+		BucketName: jsii.String(ev.Bucket),
+	})
+}
+
+// Importa la cola de un evento sqs por ARN o por nombre (en la cuenta/región del stack)
+func sqsQueue(scope constructs.Construct, id string, ev config.LambdaEvent) awssqs.IQueue {
+	arn := ev.QueueArn()
+	if arn == "" {
+		arn = *awscdk.Stack_Of(scope).FormatArn(&awscdk.ArnComponents{
+			Service:  jsii.String("sqs"),
+			Resource: jsii.String(ev.SqsQueueName()),
+		})
+	}
+	return awssqs.Queue_FromQueueArn(scope, jsii.String(id), jsii.String(arn))
+}
+
+// Opciones del event source mapping (campos sin valor = defaults de Lambda)
+func sqsProps(ev config.LambdaEvent) *awslambdaeventsources.SqsEventSourceProps {
+	props := &awslambdaeventsources.SqsEventSourceProps{}
+	if ev.BatchSize != 0 {
+		props.BatchSize = jsii.Number(float64(ev.BatchSize))
+	}
+	if ev.MaximumBatchingWindow != 0 {
+		props.MaxBatchingWindow = awscdk.Duration_Seconds(jsii.Number(float64(ev.MaximumBatchingWindow)))
+	}
+	return props
+}
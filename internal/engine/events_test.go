@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// synthTemplate sintetiza cfg (el stack local con local) en un directorio
+// temporal y devuelve los recursos del template
+func synthTemplate(t *testing.T, cfg *config.ServerlessConfig, local bool) map[string]map[string]interface{} {
+	t.Helper()
+	result, err := synth(cfg, t.TempDir(), local)
+	if err != nil {
+		t.Fatalf("Synth: %v", err)
+	}
+	data, err := os.ReadFile(result.TemplatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var template struct {
+		Resources map[string]map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &template); err != nil {
+		t.Fatal(err)
+	}
+	return template.Resources
+}
+
+// resourcesOfType devuelve los logical IDs de los recursos del tipo dado
+func resourcesOfType(resources map[string]map[string]interface{}, typ string) []string {
+	var ids []string
+	for id, res := range resources {
+		if res["Type"] == typ {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Una función con eventos http y schedule es una sola Lambda: la ruta del API
+// y la regla de EventBridge apuntan a la misma función
+func TestSynthHttpAndScheduleShareOneFunction(t *testing.T) {
+	cfg := &config.ServerlessConfig{
+		Service: "svc",
+		Stage:   "dev",
+		Functions: map[string]config.LambdaFunc{
+			"worker": {
+				FunctionName: "worker",
+				Runtime:      "nodejs20.x",
+				Handler:      "index.handler",
+				Code:         t.TempDir(),
+				Events: []config.LambdaEvent{
+					{Type: "http", Path: "/jobs", Method: "post"},
+					{Type: "schedule", Schedule: "rate(5 minutes)"},
+				},
+			},
+		},
+	}
+	resources := synthTemplate(t, cfg, false)
+
+	functions := resourcesOfType(resources, "AWS::Lambda::Function")
+	if len(functions) != 1 {
+		t.Fatalf("got %d Lambda functions %v, want 1", len(functions), functions)
+	}
+	fnID := functions[0]
+
+	methods := resourcesOfType(resources, "AWS::ApiGateway::Method")
+	if len(methods) != 1 {
+		t.Fatalf("got %d API methods, want 1", len(methods))
+	}
+	integration, _ := json.Marshal(resources[methods[0]]["Properties"].(map[string]interface{})["Integration"])
+	if !containsRef(integration, fnID) {
+		t.Errorf("POST /jobs integration %s does not invoke %s", integration, fnID)
+	}
+
+	rules := resourcesOfType(resources, "AWS::Events::Rule")
+	if len(rules) != 1 {
+		t.Fatalf("got %d schedule rules, want 1", len(rules))
+	}
+	props := resources[rules[0]]["Properties"].(map[string]interface{})
+	if props["ScheduleExpression"] != "rate(5 minutes)" {
+		t.Errorf("ScheduleExpression = %v, want rate(5 minutes)", props["ScheduleExpression"])
+	}
+	targets, _ := json.Marshal(props["Targets"])
+	if !containsRef(targets, fnID) {
+		t.Errorf("schedule targets %s do not invoke %s", targets, fnID)
+	}
+}
+
+// containsRef indica si el fragmento JSON referencia (Ref o Fn::GetAtt) al recurso id
+func containsRef(fragment []byte, id string) bool {
+	var walk func(v interface{}) bool
+	walk = func(v interface{}) bool {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if v["Ref"] == id {
+				return true
+			}
+			if att, ok := v["Fn::GetAtt"].([]interface{}); ok && len(att) > 0 && att[0] == id {
+				return true
+			}
+			for _, child := range v {
+				if walk(child) {
+					return true
+				}
+			}
+		case []interface{}:
+			for _, child := range v {
+				if walk(child) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	var v interface{}
+	return json.Unmarshal(fragment, &v) == nil && walk(v)
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsevents"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsiam"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awskms"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambdadestinations"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssns"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssqs"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// Props de una Lambda, las mismas en Synth y SynthLocal para que las
+// opciones por función lleguen igual al stack desplegado y al local
+func functionProps(scope constructs.Construct, logicalName string, fn config.LambdaFunc, functionName string, runtime awslambda.Runtime, code awslambda.Code) *awslambda.FunctionProps {
+	props := &awslambda.FunctionProps{
+		FunctionName:          jsii.String(functionName),
+		Runtime:               runtime,
+		Handler:               jsii.String(fn.Handler),
+		Code:                  code,
+		MemorySize:            jsii.Number(float64(fn.MemorySize)),
+		Timeout:               awscdk.Duration_Seconds(jsii.Number(float64(fn.Timeout))),
+		RuntimeManagementMode: runtimeManagementMode(fn.RuntimeManagement),
+	}
+
+	if fn.Architecture == config.ArchitectureArm64 {
+		props.Architecture = awslambda.Architecture_ARM_64()
+	}
+
+	// Rol existente: CDK no crea uno nuevo ni le adjunta políticas (immutable)
+	if role := fn.ExecutionRole(); role != "" {
+		props.Role = awsiam.Role_FromRoleArn(scope, jsii.String(logicalName+"ImportedRole"), jsii.String(role),
+			&awsiam.FromRoleArnOptions{Mutable: jsii.Bool(false)})
+	}
+
+	if len(fn.Environment) > 0 {
+		env := make(map[string]*string, len(fn.Environment))
+		for name, value := range fn.Environment {
+			env[name] = jsii.String(value)
+		}
+		props.Environment = &env
+	}
+
+	if len(fn.Layers) > 0 {
+		layers := make([]awslambda.ILayerVersion, 0, len(fn.Layers))
+		for i, arn := range fn.Layers {
+			layers = append(layers, awslambda.LayerVersion_FromLayerVersionArn(scope,
+				jsii.String(fmt.Sprintf("%sLayer%d", logicalName, i)), jsii.String(arn)))
+		}
+		props.Layers = &layers
+	}
+
+	// CMK para las variables de entorno en lugar de la clave administrada por Lambda
+	if fn.KmsKeyArn != "" {
+		props.EnvironmentEncryption = awskms.Key_FromKeyArn(scope, jsii.String(logicalName+"EnvKey"), jsii.String(fn.KmsKeyArn))
+	}
+
+	return props
+}
+
+// Traduce runtimeManagement al modo de CDK (nil = comportamiento por defecto de AWS)
+func runtimeManagementMode(rm *config.RuntimeManagementConfig) awslambda.RuntimeManagementMode {
+	if rm == nil {
+		return nil
+	}
+
+	switch rm.Mode {
+	case config.RuntimeManagementAuto:
+		return awslambda.RuntimeManagementMode_AUTO()
+	case config.RuntimeManagementFunctionUpdate:
+		return awslambda.RuntimeManagementMode_FUNCTION_UPDATE()
+	case config.RuntimeManagementManual:
+		return awslambda.RuntimeManagementMode_Manual(jsii.String(rm.RuntimeVersionArn))
+	default:
+		return nil
+	}
+}
+
+// Conecta la función a subnets/security groups existentes. Se fija en el
+// CfnFunction porque las props de CDK exigen un IVpc que aquí no tenemos.
+func applyVpc(lambdaFn awslambda.Function, vpc *config.VpcConfig) {
+	if vpc == nil {
+		return
+	}
+
+	cfn := lambdaFn.Node().DefaultChild().(awslambda.CfnFunction)
+	cfn.SetVpcConfig(&awslambda.CfnFunction_VpcConfigProperty{
+		SubnetIds:        jsii.Strings(vpc.SubnetIds...),
+		SecurityGroupIds: jsii.Strings(vpc.SecurityGroupIds...),
+	})
+
+	// Las ENIs requieren permisos EC2 en el rol creado por CDK (un rol importado no se modifica)
+	if role := lambdaFn.Role(); role != nil {
+		role.AddManagedPolicy(awsiam.ManagedPolicy_FromAwsManagedPolicyName(
+			jsii.String("service-role/AWSLambdaVPCAccessExecutionRole")))
+	}
+}
+
+// Agrega los iamRoleStatements de la función a la política por defecto del rol creado por CDK
+func applyIamRoleStatements(lambdaFn awslambda.Function, statements []config.IamRoleStatement) {
+	for _, s := range statements {
+		effect := awsiam.Effect_ALLOW
+		if s.Effect == config.EffectDeny {
+			effect = awsiam.Effect_DENY
+		}
+		lambdaFn.AddToRolePolicy(awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+			Effect:    effect,
+			Actions:   jsii.Strings(s.Action...),
+			Resources: jsii.Strings(s.Resource...),
+		}))
+	}
+}
+
+// Configura los destinos de las invocaciones asíncronas (schedule, sns, s3...).
+// CDK agrega al rol creado los permisos para publicar en cada destino.
+func applyDestinations(scope constructs.Construct, logicalName string, lambdaFn awslambda.Function, dest *config.DestinationsConfig) {
+	if dest == nil || (dest.OnSuccess == "" && dest.OnFailure == "") {
+		return
+	}
+
+	lambdaFn.ConfigureAsyncInvoke(&awslambda.EventInvokeConfigOptions{
+		OnSuccess: destination(scope, logicalName+"OnSuccess", dest.OnSuccess),
+		OnFailure: destination(scope, logicalName+"OnFailure", dest.OnFailure),
+	})
+}
+
+// Importa el recurso del ARN como destino según su servicio (nil si no hay ARN)
+func destination(scope constructs.Construct, id, arn string) awslambda.IDestination {
+	switch config.DestinationService(arn) {
+	case config.DestinationSqs:
+		return awslambdadestinations.NewSqsDestination(awssqs.Queue_FromQueueArn(scope, jsii.String(id), jsii.String(arn)))
+	case config.DestinationSns:
+		return awslambdadestinations.NewSnsDestination(awssns.Topic_FromTopicArn(scope, jsii.String(id), jsii.String(arn)))
+	case config.DestinationEventBridge:
+		return awslambdadestinations.NewEventBridgeDestination(awsevents.EventBus_FromEventBusArn(scope, jsii.String(id), jsii.String(arn)))
+	case config.DestinationLambda:
+		return awslambdadestinations.NewLambdaDestination(awslambda.Function_FromFunctionArn(scope, jsii.String(id), jsii.String(arn)), nil)
+	default:
+		return nil
+	}
+}
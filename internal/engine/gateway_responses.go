@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsapigateway"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// Agrega al API las respuestas de error propias de api.gatewayResponses
+func addGatewayResponses(api awsapigateway.RestApi, apiCfg *config.ApiConfig) {
+	if apiCfg == nil {
+		return
+	}
+
+	types := make([]string, 0, len(apiCfg.GatewayResponses))
+	for responseType := range apiCfg.GatewayResponses {
+		types = append(types, responseType)
+	}
+	sort.Strings(types)
+
+	for _, responseType := range types {
+		response := apiCfg.GatewayResponses[responseType]
+		opts := &awsapigateway.GatewayResponseOptions{
+			Type: awsapigateway.ResponseType_Of(jsii.String(responseType)),
+		}
+		if response.StatusCode != "" {
+			opts.StatusCode = jsii.String(response.StatusCode)
+		}
+		if len(response.Headers) > 0 {
+			headers := make(map[string]*string, len(response.Headers))
+			for name, value := range response.Headers {
+				headers[name] = jsii.String(quoteHeaderValue(value))
+			}
+			opts.ResponseHeaders = &headers
+		}
+		if len(response.Templates) > 0 {
+			templates := make(map[string]*string, len(response.Templates))
+			for contentType, template := range response.Templates {
+				templates[contentType] = jsii.String(template)
+			}
+			opts.Templates = &templates
+		}
+		api.AddGatewayResponse(jsii.String("GatewayResponse"+strings.ReplaceAll(responseType, "_", "")), opts)
+	}
+}
+
+// API Gateway exige los valores estáticos de cabecera entre comillas simples
+func quoteHeaderValue(v string) string {
+	if strings.HasPrefix(v, "'") && strings.HasSuffix(v, "'") && len(v) > 1 {
+		return v
+	}
+	return "'" + v + "'"
+}
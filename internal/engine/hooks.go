@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// StackHook permite agregar constructs propios (alarmas, dashboards, ...) al
+// stack generado sin hacer fork del engine.
+//
+// Requisitos de jsii:
+//   - Los constructs deben crearse con stack (o un hijo suyo) como scope y con
+//     IDs únicos dentro del stack; un ID repetido hace que jsii entre en panic.
+//   - El hook se ejecuta dentro del mismo proceso jsii durante el synth: no
+//     debe llamar a app.Synth() ni guardar el stack para usarlo después.
+//   - Un panic en el hook se reporta como error de synth.
+type StackHook func(stack awscdk.Stack, cfg *config.ServerlessConfig)
+
+var (
+	hooksMu    sync.Mutex
+	stackHooks []StackHook
+)
+
+// RegisterStackHook registra un hook que se ejecuta, en orden de registro,
+// después de construir los recursos estándar del stack
+func RegisterStackHook(hook StackHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	stackHooks = append(stackHooks, hook)
+}
+
+func runStackHooks(stack awscdk.Stack, cfg *config.ServerlessConfig) {
+	hooksMu.Lock()
+	hooks := append([]StackHook(nil), stackHooks...)
+	hooksMu.Unlock()
+
+	for _, hook := range hooks {
+		hook(stack, cfg)
+	}
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// Número de intentos para levantar el runtime jsii (puente Go -> Node.js)
+const jsiiStartAttempts = 3
+
+// ErrNodeRequired se devuelve cuando no hay Node.js para el runtime de CDK
+var ErrNodeRequired = errors.New("Node.js required for CDK synthesis (install Node.js or set JSII_NODE)")
+
+// CheckJsiiRuntime verifica que el runtime jsii pueda iniciarse, devolviendo un
+// error legible en lugar del panic que produce jsii cuando falla el arranque
+func CheckJsiiRuntime() error {
+	node := os.Getenv("JSII_NODE")
+	if node == "" {
+		node = "node"
+	}
+	if _, err := exec.LookPath(node); err != nil {
+		return ErrNodeRequired
+	}
+
+	var err error
+	for attempt := 1; attempt <= jsiiStartAttempts; attempt++ {
+		if err = pingJsii(); err == nil {
+			return nil
+		}
+		time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+	}
+	return fmt.Errorf("could not start the jsii runtime after %d attempts: %w", jsiiStartAttempts, err)
+}
+
+// La primera llamada a jsii arranca el proceso de Node.js
+func pingJsii() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	awscdk.Token_IsUnresolved(jsii.String("qriosls"))
+	return nil
+}
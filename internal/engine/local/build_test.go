@@ -0,0 +1,139 @@
+package local
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+	"github.com/qrioso-software/qriososls/internal/engine/local/runtime"
+)
+
+// sleepRuntime stands in for a compiler: each Build takes delay and records
+// how many builds share an output path at the same time.
+type sleepRuntime struct {
+	delay   time.Duration
+	mu      sync.Mutex
+	running map[string]int
+	overlap atomic.Bool // Two builds wrote the same output path at once
+	builds  atomic.Int32
+}
+
+func (s *sleepRuntime) Name() string { return "sleep" }
+
+func (s *sleepRuntime) Build(functionDir, outputPath string) error {
+	s.mu.Lock()
+	s.running[outputPath]++
+	if s.running[outputPath] > 1 {
+		s.overlap.Store(true)
+	}
+	s.mu.Unlock()
+
+	time.Sleep(s.delay)
+	s.builds.Add(1)
+
+	s.mu.Lock()
+	s.running[outputPath]--
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *sleepRuntime) WatchPatterns() []string                           { return nil }
+func (s *sleepRuntime) NeedsBuild() bool                                  { return true }
+func (s *sleepRuntime) StartCommand(binaryPath string) []string           { return nil }
+func (s *sleepRuntime) TestCommand(functionDir string) (*exec.Cmd, error) { return nil, nil }
+func (s *sleepRuntime) CheckEntrypoint(functionDir, handler string) error { return nil }
+
+// buildRunner returns a runner with n functions built by rt. With shared, all
+// of them use the same code directory (and so the same output path).
+func buildRunner(n int, shared bool, rt runtime.Runtime) *LocalRunner {
+	lr := &LocalRunner{
+		cfg:              &config.ServerlessConfig{RootPath: "/project", Stage: "dev", Functions: map[string]config.LambdaFunc{}},
+		opts:             Options{BuildAll: true},
+		functionRuntimes: make(map[string]runtime.Runtime),
+	}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("fn%02d", i)
+		code := "src/" + name
+		if shared {
+			code = "src/shared"
+		}
+		lr.cfg.Functions[name] = config.LambdaFunc{FunctionName: name, Runtime: "go", Code: code}
+		lr.functionRuntimes[name] = rt
+	}
+	return lr
+}
+
+func quietLogs(tb testing.TB) {
+	prev := log.Writer()
+	log.SetOutput(io.Discard)
+	tb.Cleanup(func() { log.SetOutput(prev) })
+}
+
+// Functions sharing a code directory build one after the other: their
+// builds would overwrite the same output.
+func TestBuildAllFunctionsSerializesSharedOutput(t *testing.T) {
+	quietLogs(t)
+	rt := &sleepRuntime{delay: 5 * time.Millisecond, running: map[string]int{}}
+	lr := buildRunner(6, true, rt)
+	lr.buildJobs = 4
+
+	if err := lr.buildAllFunctions(); err != nil {
+		t.Fatal(err)
+	}
+	if got := rt.builds.Load(); got != 6 {
+		t.Errorf("built %d functions, want 6", got)
+	}
+	if rt.overlap.Load() {
+		t.Error("two builds wrote the same output path at the same time")
+	}
+}
+
+// BenchmarkBuildAllFunctions compares the parallel build with building the
+// same functions one by one. Build time is simulated so only scheduling is
+// measured; jobs fixes the concurrency so results don't depend on the CPU count.
+func BenchmarkBuildAllFunctions(b *testing.B) {
+	quietLogs(b)
+	const delay = 10 * time.Millisecond
+
+	for _, n := range []int{1, 4, 16} {
+		for _, jobs := range []int{1, 4} {
+			b.Run(fmt.Sprintf("parallel/functions=%d/jobs=%d", n, jobs), func(b *testing.B) {
+				lr := buildRunner(n, false, &sleepRuntime{delay: delay, running: map[string]int{}})
+				lr.buildJobs = jobs
+				for i := 0; i < b.N; i++ {
+					if err := lr.buildAllFunctions(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+
+		b.Run(fmt.Sprintf("shared-code/functions=%d/jobs=4", n), func(b *testing.B) {
+			lr := buildRunner(n, true, &sleepRuntime{delay: delay, running: map[string]int{}})
+			lr.buildJobs = 4
+			for i := 0; i < b.N; i++ {
+				if err := lr.buildAllFunctions(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("sequential/functions=%d", n), func(b *testing.B) {
+			lr := buildRunner(n, false, &sleepRuntime{delay: delay, running: map[string]int{}})
+			names := config.SortedFunctionNames(lr.cfg.Functions)
+			for i := 0; i < b.N; i++ {
+				for _, name := range names {
+					if err := lr.build(name, lr.cfg.Functions[name], lr.functionRuntimes[name]); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// internal/engine/local/envvars.go
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/qrioso-software/qriososls/internal/util"
+)
+
+// File written next to the cloud assembly and passed to SAM as --env-vars
+const samEnvFileName = "qriosls-env.json"
+
+// User overrides for local runs, created with defaults when missing
+const userEnvFile = "env.json"
+
+// envVarsArgs returns the --env-vars arguments for a SAM run. The config
+// environment is passed so local matches the deployed functions; env.json
+// wins on conflicts.
+func (lr *LocalRunner) envVarsArgs() []string {
+	if _, err := os.Stat(userEnvFile); os.IsNotExist(err) {
+		if err := lr.createDefaultEnvFile(userEnvFile); err != nil {
+			log.Printf("⚠️ Could not create %s: %v", userEnvFile, err)
+		}
+	}
+
+	samEnvPath, err := lr.writeSamEnvFile(userEnvFile)
+	if err == nil {
+		return []string{"--env-vars", samEnvPath}
+	}
+	log.Printf("⚠️ Could not write SAM env vars (%v), using %s as-is", err, userEnvFile)
+	if _, err := os.Stat(userEnvFile); err == nil {
+		return []string{"--env-vars", userEnvFile}
+	}
+	return nil
+}
+
+// writeSamEnvFile writes the --env-vars file SAM runs with: each function's
+// environment from the config, with the user's env.json (Parameters and
+// per-function sections) applied on top so local overrides still win.
+// Keys are the function logical IDs, which the stack sets to the function name.
+func (lr *LocalRunner) writeSamEnvFile(userPath string) (string, error) {
+	vars := make(map[string]map[string]string)
+
+	if data, err := os.ReadFile(userPath); err == nil {
+		if err := json.Unmarshal(data, &vars); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", userPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	for _, fn := range lr.cfg.ActiveFunctions() {
+		if len(fn.Environment) == 0 {
+			continue
+		}
+		logicalID := util.ResolveVars(fn.FunctionName, lr.cfg.Stage)
+		merged := make(map[string]string, len(fn.Environment))
+		for name, value := range fn.Environment {
+			merged[name] = value
+		}
+		for name, value := range vars[logicalID] {
+			merged[name] = value
+		}
+		vars[logicalID] = merged
+	}
+
+	data, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(lr.outDir(), samEnvFileName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
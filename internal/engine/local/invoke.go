@@ -0,0 +1,64 @@
+// internal/engine/local/invoke.go
+package local
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/qrioso-software/qriososls/internal/progress"
+	"github.com/qrioso-software/qriososls/internal/util"
+)
+
+// Invoke builds one function, synthesizes the local stack and runs the function
+// once with `sam local invoke`, streaming its output. eventPath is a JSON event
+// file; empty sends an empty event.
+func (lr *LocalRunner) Invoke(funcName, eventPath string) error {
+	function, ok := lr.cfg.ActiveFunctions()[funcName]
+	if !ok {
+		return fmt.Errorf("function '%s' not found or not deployed in stage '%s'", funcName, lr.cfg.Stage)
+	}
+
+	// Prebuilt artifacts are deployed as-is; everything else is built fresh
+	if _, fromSource := lr.sourceFunctions()[funcName]; fromSource {
+		if err := lr.initializeRuntimes(); err != nil {
+			return err
+		}
+		if err := lr.buildFunction(funcName, function, lr.functionRuntimes[funcName]); err != nil {
+			return err
+		}
+	}
+
+	if err := lr.synthesize(); err != nil {
+		return err
+	}
+
+	// The stack overrides each function's logical ID with its resolved name
+	logicalID := util.ResolveVars(function.FunctionName, lr.cfg.Stage)
+	cmdArgs := []string{
+		"local", "invoke", logicalID,
+		"--template", lr.synth.TemplatePath,
+		"--skip-pull-image",
+	}
+	if eventPath != "" {
+		cmdArgs = append(cmdArgs, "--event", eventPath)
+	}
+	cmdArgs = append(cmdArgs, lr.envVarsArgs()...)
+
+	samBin := lr.samBin()
+	cmd := exec.Command(samBin, cmdArgs...)
+	cmd.Stdout = progress.Stdout()
+	cmd.Stderr = os.Stderr
+
+	log.Printf("🚀 Invoking %s: %s %s", funcName, samBin, strings.Join(cmdArgs, " "))
+	wait, err := lr.startTool("sam local invoke", cmd)
+	if err == nil {
+		err = wait()
+	}
+	if err := progress.Start("invoke", funcName).Done(err); err != nil {
+		return fmt.Errorf("sam local invoke failed for %s: %w", funcName, err)
+	}
+	return nil
+}
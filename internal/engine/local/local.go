@@ -2,50 +2,116 @@
 package local
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/qrioso-software/qriososls/internal/config"
+	"github.com/qrioso-software/qriososls/internal/engine"
 	"github.com/qrioso-software/qriososls/internal/engine/local/runtime"
+	"github.com/qrioso-software/qriososls/internal/progress"
 	"github.com/qrioso-software/qriososls/internal/util"
 )
 
+// Default port the local API is served on
+const DefaultAPIPort = 3000
+
+// Allowed range for Options.Port (unprivileged ports)
+const (
+	minAPIPort = 1024
+	maxAPIPort = 65535
+)
+
+// How long to wait for the watcher to report the probe file before suggesting --watch-poll
+const watchProbeTimeout = 3 * time.Second
+
+// Options holds optional settings for the local runner
+type Options struct {
+	SkipInstall bool   // Skip npm/pip install for scripting runtimes
+	Trace       bool   // Put a logging reverse proxy in front of SAM
+	TraceBodies bool   // Also log (bounded) request/response bodies when tracing
+	Verbose     bool   // Show raw compiler output on build failures
+	BuildAll    bool   // Build every function and report all failures instead of stopping at the first
+	SamBin      string // SAM CLI binary (name in PATH or path); empty = "sam"
+	Port        int    // Port the local API is served on; 0 = DefaultAPIPort
+
+	WatchPoll    bool          // Detect changes by polling mtimes instead of fsnotify
+	PollInterval time.Duration // Polling interval with WatchPoll (<= 0 = default)
+
+	OutDir      string                                   // Cloud assembly directory (relative to the project root unless absolute; empty = cdk.out)
+	ConfigFiles []string                                 // Config files (relative to the project root) watched for route changes
+	AutoSynth   bool                                     // Reload when a config file changes instead of only warning
+	LoadConfig  func() (*config.ServerlessConfig, error) // Reloads the config on reload; nil = keep the initial one
+
+	// Starts a sam subprocess; wait blocks until it exits and classifies its failure. nil = plain Start/Wait
+	StartTool func(tool string, cmd *exec.Cmd) (wait func() error, err error)
+}
+
 // LocalRunner handles local execution with hot reload capability
 type LocalRunner struct {
 	cfg              *config.ServerlessConfig
-	watcher          *fsnotify.Watcher
-	apiProcess       *os.Process
+	opts             Options
+	watcher          fileWatcher
+	watchEvents      <-chan fsnotify.Event
+	watchErrors      <-chan error
+	probePath        string        // File written once to check the watcher delivers events
+	probeSeen        chan struct{} // Signaled when the probe event arrives
+	sam              *samProcess   // Running sam local start-api
+	samMu            sync.Mutex    // Serializes SAM restarts with Stop
+	traceProxy       *TraceProxy
 	stopChan         chan struct{}
+	configStale      bool          // A config file changed since the last synth (warned once)
+	reloadChan       chan struct{} // Full rebuild + SAM restart requests (SIGHUP, "r" on stdin)
 	lastBuild        time.Time
 	buildMutex       sync.Mutex
 	mu               sync.Mutex
 	runtimeFactory   *runtime.RuntimeFactory
 	functionRuntimes map[string]runtime.Runtime
-	watchedDirs      map[string]bool // Track watched directories to avoid duplicates
+	watchedDirs      map[string]bool     // Track watched directories to avoid duplicates
+	buildJobs        int                 // Concurrent builds; 0 = one per CPU
+	synth            *engine.SynthResult // Cloud assembly SAM runs from
 }
 
 // NewLocalRunner creates a new local runner instance
-func NewLocalRunner(cfg *config.ServerlessConfig) (*LocalRunner, error) {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+func NewLocalRunner(cfg *config.ServerlessConfig, opts Options) (*LocalRunner, error) {
+	if err := validatePort(opts); err != nil {
+		return nil, err
 	}
 
-	return &LocalRunner{
+	lr := &LocalRunner{
 		cfg:              cfg,
-		watcher:          watcher,
+		opts:             opts,
+		probeSeen:        make(chan struct{}, 1),
 		stopChan:         make(chan struct{}),
+		reloadChan:       make(chan struct{}, 1),
 		runtimeFactory:   runtime.NewRuntimeFactory(),
 		functionRuntimes: make(map[string]runtime.Runtime),
 		watchedDirs:      make(map[string]bool),
-	}, nil
+	}
+
+	if opts.WatchPoll {
+		poller := NewPollWatcher(opts.PollInterval)
+		lr.watcher, lr.watchEvents, lr.watchErrors = poller, poller.Events, poller.Errors
+		return lr, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	lr.watcher, lr.watchEvents, lr.watchErrors = watcher, watcher.Events, watcher.Errors
+	return lr, nil
 }
 
 // Start initializes the local environment with hot reload
@@ -69,6 +135,11 @@ func (lr *LocalRunner) Start() error {
 		return err
 	}
 
+	// Synthesize after building so the assets pick up the build output
+	if err := lr.synthesize(); err != nil {
+		return err
+	}
+
 	// Start local API Gateway
 	if err := lr.startLocalAPI(); err != nil {
 		return err
@@ -79,15 +150,47 @@ func (lr *LocalRunner) Start() error {
 		return err
 	}
 
+	// Manual reload for changes the watcher misses
+	lr.listenForReload()
+
 	// log.Println("✅ Hot reload enabled for multiple runtimes!")
 	lr.keepAlive()
 	return nil
 }
 
+// Build initializes the runtimes and builds every function once,
+// without synthesizing or starting SAM (used by deploy --watch)
+func (lr *LocalRunner) Build() error {
+	if err := lr.initializeRuntimes(); err != nil {
+		return err
+	}
+	return lr.buildAllFunctions()
+}
+
+// BuildDirs builds only the functions packaged from their build dir (see
+// config.UsesBuildDir): synth for deploy needs them even when nothing else is built
+func (lr *LocalRunner) BuildDirs() error {
+	if err := lr.initializeRuntimes(); err != nil {
+		return err
+	}
+	active := lr.sourceFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		if !lr.cfg.UsesBuildDir(funcName) {
+			continue
+		}
+		if err := lr.buildFunction(funcName, active[funcName], lr.functionRuntimes[funcName]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // initializeRuntimes creates runtime instances for each function
 func (lr *LocalRunner) initializeRuntimes() error {
-	for funcName, function := range lr.cfg.Functions {
-		codePath := filepath.Join(lr.cfg.RootPath, filepath.Clean(function.Code))
+	active := lr.sourceFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		function := active[funcName]
+		codePath := lr.absPath(function.Code)
 		functionDir := filepath.Dir(codePath)
 
 		var rt runtime.Runtime
@@ -110,25 +213,132 @@ func (lr *LocalRunner) initializeRuntimes() error {
 			return fmt.Errorf("error determining runtime for %s: %w", funcName, err)
 		}
 
+		// Dependencies already vendored: turn the install step into a no-op
+		if lr.opts.SkipInstall || function.SkipInstall {
+			switch r := rt.(type) {
+			case *runtime.NodeJSRuntime:
+				r.SkipInstall = true
+			case *runtime.PythonRuntime:
+				r.SkipInstall = true
+			}
+		}
+
+		// Python dependencies are installed into the build dir, which is then the asset
+		if r, ok := rt.(*runtime.PythonRuntime); ok {
+			r.Requirements = lr.cfg.UsesBuildDir(funcName)
+		}
+
+		// TypeScript sources are compiled into the code directory (the asset)
+		if r, ok := rt.(*runtime.NodeJSRuntime); ok {
+			r.TypeScript = dirExists(filepath.Join(codePath, "tsconfig.json"))
+			r.Handler = function.Handler
+		}
+
+		// Limit the go.mod lookup to the project
+		if r, ok := rt.(*runtime.GolangRuntime); ok {
+			r.RootPath = lr.cfg.RootPath
+			r.Verbose = lr.opts.Verbose
+			if function.ModuleRoot != "" {
+				r.ModuleRoot = lr.absPath(function.ModuleRoot)
+			}
+			r.Arch = function.GoArch()
+			// handler como directorio del paquete main (layout cmd/<name>)
+			if h := function.Handler; h != "" && h != config.ProvidedHandler && dirExists(filepath.Join(codePath, filepath.FromSlash(h))) {
+				r.Package = filepath.Clean(filepath.FromSlash(h))
+			}
+		}
+
+		// Half-scaffolded functions fail here instead of deep inside the build (zipped code is taken as-is)
+		if info, statErr := os.Stat(codePath); statErr != nil || info.IsDir() {
+			if err := rt.CheckEntrypoint(codePath, function.Handler); err != nil {
+				return fmt.Errorf("function %s has no buildable %s entrypoint in %s: %w", funcName, rt.Name(), function.Code, err)
+			}
+		}
+
 		lr.functionRuntimes[funcName] = rt
 		log.Printf("✅ Function %s: %s runtime detected", funcName, rt.Name())
 	}
 	return nil
 }
 
-// buildAllFunctions builds all functions that require compilation
+// buildAllFunctions builds all functions that require compilation.
+// Builds run in parallel (jsii serializes synth, not the builds); functions
+// sharing an output path build one after another to avoid clobbering it.
+// Without BuildAll no new build starts after the first failure.
 func (lr *LocalRunner) buildAllFunctions() error {
-	for funcName, function := range lr.cfg.Functions {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	active := lr.sourceFunctions()
+	names := config.SortedFunctionNames(active)
+
+	groups := make(map[string][]string)
+	var order []string
+	total := 0
+	for _, funcName := range names {
 		rt := lr.functionRuntimes[funcName]
-		if rt.NeedsBuild() {
-			if err := lr.buildFunction(funcName, function, rt); err != nil {
-				return fmt.Errorf("failed to build %s: %w", funcName, err)
-			}
-		} else {
+		if !rt.NeedsBuild() {
 			log.Printf("📦 Skipping build for %s (runtime: %s)", funcName, rt.Name())
+			continue
+		}
+		outputPath := lr.getOutputPath(funcName, active[funcName], rt)
+		if _, ok := groups[outputPath]; !ok {
+			order = append(order, outputPath)
 		}
+		groups[outputPath] = append(groups[outputPath], funcName)
+		total++
 	}
-	return nil
+
+	errs := make(map[string]error)
+	var errsMu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := lr.buildJobs
+	if jobs <= 0 {
+		jobs = goruntime.NumCPU()
+	}
+	sem := make(chan struct{}, jobs)
+
+	for _, outputPath := range order {
+		wg.Add(1)
+		go func(funcNames []string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			for _, funcName := range funcNames {
+				errsMu.Lock()
+				stop := len(errs) > 0 && !lr.opts.BuildAll
+				errsMu.Unlock()
+				if stop {
+					return
+				}
+
+				if err := lr.build(funcName, active[funcName], lr.functionRuntimes[funcName]); err != nil {
+					errsMu.Lock()
+					errs[funcName] = err
+					errsMu.Unlock()
+				}
+			}
+		}(groups[outputPath])
+	}
+	wg.Wait()
+
+	// Errores en orden estable, igual que el build secuencial
+	var failures []error
+	for _, funcName := range names {
+		if err, ok := errs[funcName]; ok {
+			failures = append(failures, fmt.Errorf("failed to build %s: %w", funcName, err))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	if !lr.opts.BuildAll {
+		return failures[0]
+	}
+
+	log.Printf("❌ %d of %d builds failed", len(failures), total)
+	return errors.Join(failures...)
 }
 
 // buildFunction builds a specific function
@@ -136,9 +346,20 @@ func (lr *LocalRunner) buildFunction(funcName string, function config.LambdaFunc
 	lr.mu.Lock()
 	defer lr.mu.Unlock()
 
-	outputPath := lr.getOutputPath(function, rt)
+	return lr.build(funcName, function, rt)
+}
+
+// build runs the runtime build for a function; callers hold lr.mu
+func (lr *LocalRunner) build(funcName string, function config.LambdaFunc, rt runtime.Runtime) error {
+	sourceDir := lr.getSourceDir(function)
+	outputPath := lr.getOutputPath(funcName, function, rt)
 
-	if err := rt.Build(outputPath, outputPath); err != nil {
+	step := progress.Start("build", funcName)
+	if err := step.Done(rt.Build(sourceDir, outputPath)); err != nil {
+		var buildErr *runtime.BuildError
+		if errors.As(err, &buildErr) {
+			buildErr.Function = funcName
+		}
 		return fmt.Errorf("build failed for %s: %w", funcName, err)
 	}
 
@@ -146,9 +367,14 @@ func (lr *LocalRunner) buildFunction(funcName string, function config.LambdaFunc
 	return nil
 }
 
+// getSourceDir determines the directory holding the function sources
+func (lr *LocalRunner) getSourceDir(function config.LambdaFunc) string {
+	return lr.absPath(function.Code)
+}
+
 // getOutputPath determines the output path based on runtime type
-func (lr *LocalRunner) getOutputPath(function config.LambdaFunc, rt runtime.Runtime) string {
-	codePath := filepath.Join(lr.cfg.RootPath, filepath.Clean(function.Code))
+func (lr *LocalRunner) getOutputPath(funcName string, function config.LambdaFunc, rt runtime.Runtime) string {
+	codePath := lr.absPath(function.Code)
 
 	switch rt.(type) {
 	case *runtime.GolangRuntime:
@@ -156,7 +382,8 @@ func (lr *LocalRunner) getOutputPath(function config.LambdaFunc, rt runtime.Runt
 	case *runtime.NodeJSRuntime:
 		return codePath // Main JS file
 	case *runtime.PythonRuntime:
-		return filepath.Dir(codePath) // Entire directory
+		// Sources plus installed packages, keeping the source tree clean
+		return lr.absPath(lr.cfg.FunctionAssetPath(funcName))
 	default:
 		return codePath
 	}
@@ -164,8 +391,10 @@ func (lr *LocalRunner) getOutputPath(function config.LambdaFunc, rt runtime.Runt
 
 // debugFunctionInfo displays detailed debug information
 func (lr *LocalRunner) debugFunctionInfo() {
-	for funcName, function := range lr.cfg.Functions {
-		codePath := filepath.Join(lr.cfg.RootPath, filepath.Clean(function.Code))
+	active := lr.sourceFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		function := active[funcName]
+		codePath := lr.absPath(function.Code)
 		functionDir := filepath.Dir(codePath)
 
 		log.Printf("   Function: %s", funcName)
@@ -191,10 +420,46 @@ func (lr *LocalRunner) debugFunctionInfo() {
 
 // setupFileWatchers configures file watchers based on runtime patterns
 func (lr *LocalRunner) setupFileWatchers() error {
+	lr.watchFunctionDirs()
 
-	for funcName, function := range lr.cfg.Functions {
+	// Config edits (routes, functions) only take effect after a new synth
+	for _, file := range lr.opts.ConfigFiles {
+		if err := lr.addWatchedDir(filepath.Dir(lr.absPath(file))); err != nil {
+			log.Printf("⚠️ Could not watch %s: %v", file, err)
+		}
+	}
+
+	if lr.opts.WatchPoll {
+		log.Printf("🔁 Polling %d directories for changes every %s", len(lr.watchedDirs), lr.pollInterval())
+		go lr.watchForChanges()
+		return nil
+	}
+
+	dirs := make([]string, 0, len(lr.watchedDirs))
+	for dir := range lr.watchedDirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	if len(dirs) > 0 {
+		// .tmp: shouldIgnoreEvent keeps the probe from triggering a rebuild
+		lr.probePath = filepath.Join(dirs[0], ".qriosls-watch-probe.tmp")
+	}
+
+	go lr.watchForChanges()
+	if lr.probePath != "" {
+		go lr.probeWatcher()
+	}
+	return nil
+}
+
+// watchFunctionDirs watches the code directory of every source function.
+// Directories already watched are skipped, so a reload only adds new ones.
+func (lr *LocalRunner) watchFunctionDirs() {
+	active := lr.sourceFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		function := active[funcName]
 		rt := lr.functionRuntimes[funcName]
-		completeCodePath := filepath.Join(lr.cfg.RootPath, function.Code)
+		completeCodePath := lr.absPath(function.Code)
 
 		// Watch the main function directory
 		if err := lr.addWatchedDir(completeCodePath); err != nil {
@@ -202,7 +467,7 @@ func (lr *LocalRunner) setupFileWatchers() error {
 		}
 		// Add runtime-specific watch patterns
 		for _, pattern := range rt.WatchPatterns() {
-			absPattern := filepath.Join(lr.cfg.RootPath, function.Code, pattern)
+			absPattern := filepath.Join(completeCodePath, pattern)
 			matches, err := filepath.Glob(absPattern)
 			if err != nil {
 				continue
@@ -216,13 +481,36 @@ func (lr *LocalRunner) setupFileWatchers() error {
 			}
 		}
 	}
+}
 
-	go lr.watchForChanges()
-	return nil
+// pollInterval returns the effective polling interval
+func (lr *LocalRunner) pollInterval() time.Duration {
+	if lr.opts.PollInterval > 0 {
+		return lr.opts.PollInterval
+	}
+	return defaultPollInterval
+}
+
+// probeWatcher writes a temporary file in a watched directory and suggests
+// --watch-poll when fsnotify never reports it (network mounts, Docker volumes)
+func (lr *LocalRunner) probeWatcher() {
+	if err := os.WriteFile(lr.probePath, []byte("qriosls"), 0644); err != nil {
+		return
+	}
+	defer os.Remove(lr.probePath)
+
+	select {
+	case <-lr.probeSeen:
+	case <-time.After(watchProbeTimeout):
+		log.Printf("⚠️ No file events received from %s: hot reload may not work on this filesystem (network mount or Docker volume?). Try --watch-poll",
+			filepath.Dir(lr.probePath))
+	case <-lr.stopChan:
+	}
 }
 
 // addWatchedDir adds a directory to watch list avoiding duplicates
 func (lr *LocalRunner) addWatchedDir(dirPath string) error {
+	dirPath = filepath.Clean(dirPath)
 	if lr.watchedDirs[dirPath] {
 		return nil // Already watching
 	}
@@ -250,16 +538,29 @@ func (lr *LocalRunner) watchForChanges() {
 
 	for {
 		select {
-		case event, ok := <-lr.watcher.Events:
+		case event, ok := <-lr.watchEvents:
 			if !ok {
 				return
 			}
 
+			if lr.probePath != "" && event.Name == lr.probePath {
+				select {
+				case lr.probeSeen <- struct{}{}:
+				default:
+				}
+				continue
+			}
+
 			// Ignore CHMOD events and temporary files
 			if event.Op == fsnotify.Chmod || lr.shouldIgnoreEvent(event) {
 				continue
 			}
 
+			if lr.isConfigFile(event.Name) {
+				lr.handleConfigChange(event.Name)
+				continue
+			}
+
 			// Handle file creation events
 			if event.Op&fsnotify.Create == fsnotify.Create || event.Op&fsnotify.Write == fsnotify.Write {
 				lr.handleFileCreation(event.Name)
@@ -274,6 +575,12 @@ func (lr *LocalRunner) watchForChanges() {
 				debounceTimer.Reset(800 * time.Millisecond)
 			}
 
+		case <-lr.reloadChan:
+			// The full rebuild covers whatever was pending
+			changedFunctions = nil
+			changeSet = make(map[string]bool)
+			lr.reload()
+
 		case <-debounceTimer.C:
 			if len(changedFunctions) > 0 {
 				lr.handleFileChange(changedFunctions)
@@ -281,7 +588,7 @@ func (lr *LocalRunner) watchForChanges() {
 				changeSet = make(map[string]bool)
 			}
 
-		case err, ok := <-lr.watcher.Errors:
+		case err, ok := <-lr.watchErrors:
 			if !ok {
 				return
 			}
@@ -301,9 +608,11 @@ func (lr *LocalRunner) shouldIgnoreEvent(event fsnotify.Event) bool {
 		"/.git/", "/node_modules/", ".idea/",
 	}
 
+	// Patterns use forward slashes; normalize Windows separators before matching
+	name := filepath.ToSlash(event.Name)
 	fileName := filepath.Base(event.Name)
 	for _, pattern := range ignorePatterns {
-		if strings.Contains(event.Name, pattern) || strings.HasSuffix(fileName, pattern) {
+		if strings.Contains(name, pattern) || strings.HasSuffix(fileName, pattern) {
 			return true
 		}
 	}
@@ -314,32 +623,48 @@ func (lr *LocalRunner) shouldIgnoreEvent(event fsnotify.Event) bool {
 func (lr *LocalRunner) handleFileCreation(filePath string) {
 
 	if funcName := lr.findFunctionByPath(filePath); funcName != "" {
-		hash := util.Sha256Hash(funcName)
-		assetDir := fmt.Sprintf("%s/cdk.out/asset.%s", lr.cfg.RootPath, hash)
+		// Same hash the engine uses for the local asset, shared by functions with the same code
+		hash := util.Sha256Hash(engine.LocalAssetHash(lr.cfg.FunctionAssetPath(funcName)))
+		assetDir := filepath.Join(lr.synth.OutDir, "asset."+hash)
 		if err := util.CopyCode(filePath, assetDir); err != nil {
 			log.Printf("⚠️ Error copying file: %v", err)
 		}
 	}
 }
 
-// findFunctionByPath finds the function associated with a file path
+// findFunctionByPath finds the function associated with a file path.
+// With nested code directories (src/api and src/api/v2) the most specific one wins.
 func (lr *LocalRunner) findFunctionByPath(filePath string) string {
-	for funcName, function := range lr.cfg.Functions {
-		codeDir := filepath.Dir(function.Code)
-		absCodeDir := filepath.Join(lr.cfg.RootPath, codeDir)
+	if lr.shouldIgnorePath(filePath) {
+		return ""
+	}
+
+	match, matchLen := "", -1
+	active := lr.sourceFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		absCodeDir := lr.getSourceDir(active[funcName])
 
-		if strings.HasPrefix(filePath, absCodeDir) && !lr.shouldIgnorePath(filePath) {
-			return funcName
+		if isWithinDir(filePath, absCodeDir) && len(absCodeDir) > matchLen {
+			match, matchLen = funcName, len(absCodeDir)
 		}
 	}
-	return ""
+	return match
 }
 
 // shouldIgnorePath checks if a path should be ignored
 func (lr *LocalRunner) shouldIgnorePath(path string) bool {
-	ignoreDirs := []string{".git", "node_modules", "cdk.out", "tmp"}
-	for _, dir := range ignoreDirs {
-		if strings.Contains(path, dir) {
+	if isWithinDir(path, lr.outDir()) {
+		return true
+	}
+
+	// Whole directory names below the project root: a project under /tmp or a
+	// file like attempts.js must still be watched
+	ignoreDirs := map[string]bool{".git": true, "node_modules": true, "cdk.out": true, "tmp": true}
+	if rel, err := filepath.Rel(lr.cfg.RootPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(path), "/") {
+		if ignoreDirs[segment] {
 			return true
 		}
 	}
@@ -374,59 +699,198 @@ func (lr *LocalRunner) Stop() {
 		close(lr.stopChan)
 	}
 
-	if lr.apiProcess != nil {
-		log.Println("🛑 Stopping SAM CLI...")
-		lr.apiProcess.Kill()
+	if lr.traceProxy != nil {
+		lr.traceProxy.Stop()
 	}
 
+	lr.samMu.Lock()
+	lr.stopSam()
+	lr.samMu.Unlock()
+
 	if lr.watcher != nil {
 		lr.watcher.Close()
 	}
 }
 
+// synthesize builds the cloud assembly in-process and records where it landed
+func (lr *LocalRunner) synthesize() error {
+	step := progress.Start("synth", "")
+	result, err := engine.SynthLocal(lr.cfg, lr.outDir())
+	if err := step.Done(err); err != nil {
+		return fmt.Errorf("error synthesizing local stack: %w", err)
+	}
+
+	lr.synth = result
+	log.Printf("✅ Synthesized %s → %s", result.StackName, result.TemplatePath)
+	return nil
+}
+
 // startLocalAPI starts the local API Gateway using SAM CLI
 func (lr *LocalRunner) startLocalAPI() error {
+	samPort := lr.samPort()
+	if err := lr.restartSam(samPort); err != nil {
+		return err
+	}
 
-	templatePath := fmt.Sprintf("cdk.out/%s-%s.template.json", lr.cfg.Service, lr.cfg.Stage)
-	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-		return fmt.Errorf("CDK template not found. Run 'qriosls synth' first: %w", err)
+	if lr.opts.Trace {
+		proxy, err := NewTraceProxy(
+			fmt.Sprintf("127.0.0.1:%d", lr.apiPort()),
+			fmt.Sprintf("http://127.0.0.1:%d", samPort),
+			lr.opts.TraceBodies,
+			defaultTraceBodyLimit,
+		)
+		if err != nil {
+			return err
+		}
+		if err := proxy.Start(); err != nil {
+			return err
+		}
+		lr.traceProxy = proxy
+		log.Printf("🔎 Tracing requests on http://127.0.0.1:%d → SAM on port %d", lr.apiPort(), samPort)
 	}
 
-	envPath := "env.json"
-	if _, err := os.Stat(envPath); os.IsNotExist(err) {
-		if err := lr.createDefaultEnvFile(envPath); err != nil {
-			log.Printf("⚠️ Could not create env.json: %v", err)
+	time.Sleep(2 * time.Second)
+	log.Printf("🌐 API available at http://127.0.0.1:%d", lr.apiPort())
+	return nil
+}
+
+// apiPort returns the public port of the local API (the proxy's when tracing).
+func (lr *LocalRunner) apiPort() int {
+	if lr.opts.Port != 0 {
+		return lr.opts.Port
+	}
+	return DefaultAPIPort
+}
+
+// validatePort checks Options.Port before anything is built or SAM is started.
+// With tracing SAM also takes the next port, so that one must be in range too.
+func validatePort(opts Options) error {
+	if opts.Port == 0 {
+		return nil
+	}
+	if opts.Port < minAPIPort || opts.Port > maxAPIPort {
+		return fmt.Errorf("port %d is out of range (%d-%d)", opts.Port, minAPIPort, maxAPIPort)
+	}
+	if opts.Trace && opts.Port == maxAPIPort {
+		return fmt.Errorf("port %d leaves no port for SAM behind the trace proxy (it uses port+1)", opts.Port)
+	}
+	return nil
+}
+
+// samPort returns the port SAM listens on.
+// With tracing, SAM moves to the next port and the proxy takes the public one.
+func (lr *LocalRunner) samPort() int {
+	if lr.opts.Trace {
+		return lr.apiPort() + 1
+	}
+	return lr.apiPort()
+}
+
+// startSam launches `sam local start-api` on the given port from the current synth output.
+// A template older than the config or sources is re-synthesized with AutoSynth, else reported.
+func (lr *LocalRunner) startSam(samPort int) error {
+	if reason := lr.staleTemplateReason(); reason != "" {
+		if !lr.opts.AutoSynth {
+			log.Printf("⚠️ Serving a stale template (%s): routes may not match the config. Reload with r + Enter or use --auto-synth", reason)
+		} else if err := lr.synthesize(); err != nil {
+			return err
 		}
 	}
 
+	templatePath := lr.synth.TemplatePath
+
 	cmdArgs := []string{
 		"local", "start-api",
 		"--template", templatePath,
-		"--port", "3000",
+		"--port", strconv.Itoa(samPort),
 		"--warm-containers", "LAZY",
 		"--skip-pull-image",
 	}
+	cmdArgs = append(cmdArgs, lr.envVarsArgs()...)
 
-	if _, err := os.Stat(envPath); err == nil {
-		cmdArgs = append(cmdArgs, "--env-vars", envPath)
-	}
-
-	cmd := exec.Command("sam", cmdArgs...)
-	cmd.Stdout = os.Stdout
+	samBin := lr.samBin()
+	cmd := exec.Command(samBin, cmdArgs...)
+	cmd.Stdout = progress.Stdout()
 	cmd.Stderr = os.Stderr
 
-	log.Printf("🚀 Starting SAM CLI: sam %s", strings.Join(cmdArgs, " "))
+	log.Printf("🚀 Starting SAM CLI: %s %s", samBin, strings.Join(cmdArgs, " "))
 
-	if err := cmd.Start(); err != nil {
+	wait, err := lr.startTool("sam local start-api", cmd)
+	if err != nil {
 		return fmt.Errorf("error starting SAM CLI: %w", err)
 	}
 
-	lr.apiProcess = cmd.Process
-
-	time.Sleep(2 * time.Second)
+	lr.sam = watchSam(cmd.Process, wait)
 	return nil
 }
 
+// samProcess is a running `sam local start-api`
+type samProcess struct {
+	process *os.Process
+	stopped atomic.Bool   // Killed by stopSam, so its exit is not a failure
+	exited  chan struct{} // Closed once the process has exited
+}
+
+// watchSam waits on a started SAM process in the background and reports
+// its classified failure unless the runner stopped it.
+func watchSam(process *os.Process, wait func() error) *samProcess {
+	sam := &samProcess{process: process, exited: make(chan struct{})}
+	go func() {
+		defer close(sam.exited)
+		if err := wait(); err != nil && !sam.stopped.Load() {
+			log.Printf("❌ SAM CLI exited: %v", err)
+		}
+	}()
+	return sam
+}
+
+// restartSam stops the running SAM, if any, and starts a new one on port.
+// Once the runner is stopped nothing is started.
+func (lr *LocalRunner) restartSam(port int) error {
+	lr.samMu.Lock()
+	defer lr.samMu.Unlock()
+
+	lr.stopSam()
+	select {
+	case <-lr.stopChan:
+		return errors.New("local runner is stopped")
+	default:
+	}
+	return lr.startSam(port)
+}
+
+// stopSam kills SAM and waits for it to exit, freeing its port. Callers hold samMu.
+func (lr *LocalRunner) stopSam() {
+	if lr.sam == nil {
+		return
+	}
+	log.Println("🛑 Stopping SAM CLI...")
+	lr.sam.stopped.Store(true)
+	lr.sam.process.Kill()
+	<-lr.sam.exited
+	lr.sam = nil
+}
+
+// startTool starts a sam subprocess through Options.StartTool, so its
+// failures are classified like the cdk ones.
+func (lr *LocalRunner) startTool(tool string, cmd *exec.Cmd) (func() error, error) {
+	if lr.opts.StartTool != nil {
+		return lr.opts.StartTool(tool, cmd)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd.Wait, nil
+}
+
+// samBin returns the SAM CLI binary to run
+func (lr *LocalRunner) samBin() string {
+	if lr.opts.SamBin == "" {
+		return "sam"
+	}
+	return lr.opts.SamBin
+}
+
 // createDefaultEnvFile creates a default environment file
 func (lr *LocalRunner) createDefaultEnvFile(path string) error {
 	envContent := `{
@@ -444,3 +908,50 @@ func dirExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// sourceFunctions returns the active functions built from source.
+// Functions with a prebuilt artifact are deployed as-is and never built or watched.
+func (lr *LocalRunner) sourceFunctions() map[string]config.LambdaFunc {
+	functions := make(map[string]config.LambdaFunc)
+	for funcName, function := range lr.cfg.ActiveFunctions() {
+		if function.Artifact == "" {
+			functions[funcName] = function
+		}
+	}
+	return functions
+}
+
+// outDir returns the absolute cloud assembly directory SAM serves from
+func (lr *LocalRunner) outDir() string {
+	dir := lr.opts.OutDir
+	if dir == "" {
+		dir = "cdk.out"
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return lr.absPath(dir)
+}
+
+// absPath resolves a config path (always written with forward slashes)
+// against the project root using the platform separator
+func (lr *LocalRunner) absPath(p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(lr.cfg.RootPath, filepath.Clean(filepath.FromSlash(p)))
+}
+
+// isWithinDir reports whether path is dir itself or lies beneath it.
+// Both sides are cleaned and slash-normalized so Windows paths compare correctly.
+func isWithinDir(path, dir string) bool {
+	p := filepath.ToSlash(filepath.Clean(path))
+	d := strings.TrimSuffix(filepath.ToSlash(filepath.Clean(dir)), "/")
+
+	// NTFS is case-insensitive
+	if goruntime.GOOS == "windows" {
+		p, d = strings.ToLower(p), strings.ToLower(d)
+	}
+
+	return p == d || strings.HasPrefix(p, d+"/")
+}
@@ -0,0 +1,51 @@
+package local
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// nestedRunner returns a runner whose functions live in nested code directories
+func nestedRunner(root string) *LocalRunner {
+	fn := func(code string) config.LambdaFunc {
+		return config.LambdaFunc{FunctionName: filepath.Base(code), Runtime: "nodejs20.x", Handler: "index.handler", Code: code}
+	}
+	return &LocalRunner{cfg: &config.ServerlessConfig{
+		RootPath: root,
+		Stage:    "dev",
+		Functions: map[string]config.LambdaFunc{
+			"api":    fn("src/api"),
+			"apiV2":  fn("src/api/v2"),
+			"worker": fn("src/worker"),
+		},
+	}}
+}
+
+func TestFindFunctionByPathPicksMostSpecificDir(t *testing.T) {
+	root := filepath.FromSlash("/tmp/project")
+	lr := nestedRunner(root)
+
+	tests := map[string]string{
+		"src/api/index.js":              "api",
+		"src/api/lib/db.js":             "api",
+		"src/api/v2/index.js":           "apiV2",
+		"src/api/v2/handlers/get.js":    "apiV2",
+		"src/api/v2.js":                 "api",
+		"src/worker/attempts.js":        "worker",
+		"src/shared/util.js":            "",
+		"src/api/node_modules/x/y.js":   "",
+		"src/api/tmp/scratch.js":        "",
+		"cdk.out/asset.abc/index.js":    "",
+		"src/worker/.git/HEAD":          "",
+		"README.md":                     "",
+		"src/api-legacy/index.js":       "",
+		"src/api/v2/../../api/index.js": "api",
+	}
+	for file, want := range tests {
+		if got := lr.findFunctionByPath(filepath.Join(root, filepath.FromSlash(file))); got != want {
+			t.Errorf("findFunctionByPath(%s) = %q, want %q", file, got, want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// internal/engine/local/poll.go
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultPollInterval is how often PollWatcher rescans the watched directories
+const defaultPollInterval = time.Second
+
+// fileWatcher is the backend LocalRunner watches directories through
+type fileWatcher interface {
+	Add(dir string) error
+	Close() error
+}
+
+// fileState is what PollWatcher compares between scans
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// PollWatcher detects changes by scanning file mtimes, for filesystems where
+// fsnotify never fires (network mounts, some Docker volumes). Like fsnotify,
+// it watches the files directly inside each directory, not subdirectories.
+type PollWatcher struct {
+	Events chan fsnotify.Event
+	Errors chan error
+
+	interval time.Duration
+	mu       sync.Mutex
+	dirs     map[string]map[string]fileState
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewPollWatcher starts a poller that rescans every interval (<= 0 = default)
+func NewPollWatcher(interval time.Duration) *PollWatcher {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	p := &PollWatcher{
+		Events:   make(chan fsnotify.Event),
+		Errors:   make(chan error),
+		interval: interval,
+		dirs:     make(map[string]map[string]fileState),
+		done:     make(chan struct{}),
+	}
+	go p.loop()
+	return p
+}
+
+// Add starts watching dir; its current files are the baseline
+func (p *PollWatcher) Add(dir string) error {
+	files, err := scanDir(dir)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dirs[filepath.Clean(dir)] = files
+	return nil
+}
+
+// Close stops polling and closes the event channels
+func (p *PollWatcher) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+func (p *PollWatcher) loop() {
+	defer close(p.Events)
+	defer close(p.Errors)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, event := range p.scan() {
+				select {
+				case p.Events <- event:
+				case <-p.done:
+					return
+				}
+			}
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// scan compares every watched directory against its last snapshot
+func (p *PollWatcher) scan() []fsnotify.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var events []fsnotify.Event
+	for dir, before := range p.dirs {
+		after, err := scanDir(dir)
+		if err != nil {
+			// The directory is gone: report its files as removed
+			after = map[string]fileState{}
+		}
+
+		for name, state := range after {
+			old, ok := before[name]
+			switch {
+			case !ok:
+				events = append(events, fsnotify.Event{Name: name, Op: fsnotify.Create})
+			case !state.modTime.Equal(old.modTime) || state.size != old.size:
+				events = append(events, fsnotify.Event{Name: name, Op: fsnotify.Write})
+			}
+		}
+		for name := range before {
+			if _, ok := after[name]; !ok {
+				events = append(events, fsnotify.Event{Name: name, Op: fsnotify.Remove})
+			}
+		}
+		p.dirs[dir] = after
+	}
+	return events
+}
+
+// scanDir snapshots the regular files directly inside dir
+func scanDir(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed between ReadDir and Info
+		}
+		files[filepath.Join(dir, entry.Name())] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return files, nil
+}
@@ -0,0 +1,109 @@
+// internal/engine/local/reload.go
+package local
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+	"github.com/qrioso-software/qriososls/internal/engine/local/runtime"
+)
+
+// Reload asks the running server for a full rebuild and SAM restart.
+// Requests made while a reload is pending are merged into it.
+func (lr *LocalRunner) Reload() {
+	select {
+	case lr.reloadChan <- struct{}{}:
+	default:
+	}
+}
+
+// listenForReload turns SIGHUP and an "r" line on stdin into Reload calls,
+// a safety valve for changes the watcher misses (e.g. generated files)
+func (lr *LocalRunner) listenForReload() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-hup:
+				log.Println("🔄 SIGHUP received, reloading")
+				lr.Reload()
+			case <-lr.stopChan:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+			case "r", "reload":
+				lr.Reload()
+			}
+		}
+	}()
+
+	log.Println("💡 Press r + Enter (or send SIGHUP) to force a full rebuild and SAM restart")
+}
+
+// swapConfig replaces the config and the function runtimes under the build
+// lock, so no build sees a half-swapped runner. On error the previous ones stay.
+func (lr *LocalRunner) swapConfig(cfg *config.ServerlessConfig) error {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	prevCfg, prevRuntimes := lr.cfg, lr.functionRuntimes
+	lr.cfg, lr.functionRuntimes = cfg, make(map[string]runtime.Runtime)
+	if err := lr.initializeRuntimes(); err != nil {
+		lr.cfg, lr.functionRuntimes = prevCfg, prevRuntimes
+		return err
+	}
+	return nil
+}
+
+// reload rebuilds every function, re-synthesizes and restarts SAM on the same port.
+// The trace proxy, if any, keeps running and reconnects to the new SAM.
+func (lr *LocalRunner) reload() {
+	log.Println("🔄 Reloading: full rebuild and SAM restart")
+	start := time.Now()
+
+	// Pick up route and function changes made to the config
+	if lr.opts.LoadConfig != nil {
+		cfg, err := lr.opts.LoadConfig()
+		if err != nil {
+			log.Printf("❌ Reload aborted, config is invalid: %v", err)
+			return
+		}
+		if err := lr.swapConfig(cfg); err != nil {
+			log.Printf("❌ Reload aborted: %v", err)
+			return
+		}
+		// Functions added by the edit are watched like the initial ones
+		lr.watchFunctionDirs()
+	}
+
+	if err := lr.buildAllFunctions(); err != nil {
+		log.Printf("❌ Reload aborted, build failed: %v", err)
+		return
+	}
+	if err := lr.synthesize(); err != nil {
+		log.Printf("❌ Reload aborted: %v", err)
+		return
+	}
+
+	if err := lr.restartSam(lr.samPort()); err != nil {
+		log.Printf("❌ Reload failed to restart SAM CLI: %v", err)
+		return
+	}
+
+	lr.configStale = false
+	log.Printf("✅ Reloaded in %s", time.Since(start).Round(time.Millisecond))
+}
@@ -0,0 +1,71 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+	"github.com/qrioso-software/qriososls/internal/engine/local/runtime"
+)
+
+// nodeConfig returns a config with one Node.js function per name, each in src/<name>
+func nodeConfig(root string, names ...string) *config.ServerlessConfig {
+	cfg := &config.ServerlessConfig{RootPath: root, Stage: "dev", Functions: map[string]config.LambdaFunc{}}
+	for _, name := range names {
+		cfg.Functions[name] = config.LambdaFunc{FunctionName: name, Runtime: "nodejs20.x", Handler: "index.handler", Code: "src/" + name}
+	}
+	return cfg
+}
+
+func TestReloadedConfigWatchesNewFunctions(t *testing.T) {
+	quietLogs(t)
+	root := t.TempDir()
+	for _, name := range []string{"orders", "users"} {
+		dir := filepath.Join(root, "src", name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte("exports.handler = async () => ({})\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	watcher := NewPollWatcher(time.Hour)
+	defer watcher.Close()
+	lr := &LocalRunner{
+		cfg:              nodeConfig(root, "orders"),
+		watcher:          watcher,
+		runtimeFactory:   runtime.NewRuntimeFactory(),
+		functionRuntimes: make(map[string]runtime.Runtime),
+		watchedDirs:      make(map[string]bool),
+	}
+	if err := lr.initializeRuntimes(); err != nil {
+		t.Fatal(err)
+	}
+	lr.watchFunctionDirs()
+
+	// The edited config adds users
+	if err := lr.swapConfig(nodeConfig(root, "orders", "users")); err != nil {
+		t.Fatalf("swapConfig: %v", err)
+	}
+	lr.watchFunctionDirs()
+	for _, name := range []string{"orders", "users"} {
+		if dir := filepath.Join(root, "src", name); !lr.watchedDirs[dir] {
+			t.Errorf("%s is not watched after the reload", dir)
+		}
+	}
+	if lr.functionRuntimes["users"] == nil {
+		t.Error("the added function has no runtime")
+	}
+
+	// A function without an entrypoint aborts the swap and keeps the previous config
+	previous := lr.cfg
+	if err := lr.swapConfig(nodeConfig(root, "orders", "users", "billing")); err == nil {
+		t.Fatal("swapConfig accepted a function without an entrypoint")
+	}
+	if lr.cfg != previous || len(lr.functionRuntimes) != 2 {
+		t.Errorf("a failed swap changed the runner: %d functions, %d runtimes", len(lr.cfg.Functions), len(lr.functionRuntimes))
+	}
+}
@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic es un error del compilador ubicado en file:line:col
+type Diagnostic struct {
+	File    string
+	Line    int
+	Col     int // 0 si el compilador no la reporta
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Col > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Col, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+}
+
+// BuildError es el error tipado de una compilación fallida, con los
+// diagnósticos ya parseados y la salida cruda del compilador
+type BuildError struct {
+	Function    string // Lo completa el runner que invocó el build
+	Diagnostics []Diagnostic
+	Output      string // stderr completo del compilador
+	Err         error
+}
+
+func (e *BuildError) Error() string {
+	switch len(e.Diagnostics) {
+	case 0:
+		return fmt.Sprintf("go build failed: %v", e.Err)
+	case 1:
+		return fmt.Sprintf("go build failed: %s", e.Diagnostics[0])
+	default:
+		return fmt.Sprintf("go build failed: %s (and %d more)", e.Diagnostics[0], len(e.Diagnostics)-1)
+	}
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// Grouped devuelve los diagnósticos agrupados por archivo, con los archivos ordenados
+func (e *BuildError) Grouped() (files []string, byFile map[string][]Diagnostic) {
+	byFile = make(map[string][]Diagnostic)
+	for _, d := range e.Diagnostics {
+		if _, ok := byFile[d.File]; !ok {
+			files = append(files, d.File)
+		}
+		byFile[d.File] = append(byFile[d.File], d)
+	}
+	sort.Strings(files)
+	return files, byFile
+}
+
+var reGoDiagnostic = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// parseGoDiagnostics extrae los errores file:line:col de la salida de go build.
+// Las líneas indentadas con tab se agregan al mensaje anterior.
+func parseGoDiagnostics(output string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "\t") && len(diags) > 0 {
+			last := &diags[len(diags)-1]
+			last.Message += "; " + strings.TrimSpace(line)
+			continue
+		}
+
+		m := reGoDiagnostic.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		diags = append(diags, Diagnostic{
+			File:    strings.TrimPrefix(m[1], "./"),
+			Line:    lineNo,
+			Col:     col,
+			Message: m[4],
+		})
+	}
+	return diags
+}
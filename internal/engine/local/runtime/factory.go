@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/qrioso-software/qriososls/internal/config"
 )
 
 // RuntimeFactory crea instancias de runtimes basado en la configuración
@@ -20,14 +22,18 @@ func (f *RuntimeFactory) GetRuntime(awsRuntime string) (Runtime, error) {
 	runtime := strings.ToLower(awsRuntime)
 
 	switch {
-	case runtime == "provided.al2" || runtime == "provided":
+	case config.IsProvidedRuntime(runtime):
 		return &GolangRuntime{}, nil
 	case strings.HasPrefix(runtime, "go"):
 		return &GolangRuntime{}, nil
 	case strings.HasPrefix(runtime, "node"):
-		return &NodeJSRuntime{}, nil
+		return &NodeJSRuntime{
+			Version: strings.TrimSuffix(strings.TrimPrefix(config.CanonicalRuntime(awsRuntime), "nodejs"), ".x"),
+		}, nil
 	case strings.HasPrefix(runtime, "python"):
-		return &PythonRuntime{}, nil
+		return &PythonRuntime{
+			Version: strings.TrimPrefix(config.CanonicalRuntime(awsRuntime), "python"),
+		}, nil
 	// case runtime == "java11" || runtime == "java17" || runtime == "java21":
 	// 	return &JavaRuntime{}, nil // ¡Podrías agregar esto después!
 	// case runtime == "ruby3.2":
@@ -62,8 +68,10 @@ func hasGoFiles(dir string) bool {
 }
 
 func hasNodeJSFiles(dir string) bool {
-	if _, err := os.Stat(filepath.Join(dir, "package.json")); err == nil {
-		return true
+	for _, name := range []string{"package.json", "tsconfig.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
 	}
 	files, _ := filepath.Glob(filepath.Join(dir, "*.js"))
 	return len(files) > 0
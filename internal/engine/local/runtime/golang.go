@@ -2,14 +2,36 @@ package runtime
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
-type GolangRuntime struct{}
+// ErrGoModNotFound indica que no hay go.mod entre el código y la raíz del proyecto
+var ErrGoModNotFound = errors.New("no go.mod found")
+
+type GolangRuntime struct {
+	RootPath string // Límite superior de la búsqueda de go.mod (vacío = hasta la raíz del disco)
+	Verbose  bool   // Mostrar el stderr crudo de go build además de los diagnósticos
+
+	// Directorio con el go.mod desde el que se compila (vacío = el go.mod más
+	// cercano al handler). Permite importar paquetes internal/ del módulo.
+	ModuleRoot string
+
+	// Paquete main relativo al code (p. ej. "cmd/handler"); vacío = la raíz del code.
+	// El bootstrap se sigue generando en la raíz del code.
+	Package string
+
+	// GOARCH de la arquitectura de la función (vacío = amd64)
+	Arch string
+}
 
 func (g *GolangRuntime) Name() string {
 	return "golang"
@@ -18,20 +40,41 @@ func (g *GolangRuntime) Name() string {
 func (g *GolangRuntime) Build(functionDir string, outputPath string) error {
 	log.Printf("🔨 Building Go function in: %s", functionDir)
 
-	// Crear directorio de output si no existe
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+	mainDir := functionDir
+	if g.Package != "" {
+		mainDir = filepath.Join(functionDir, filepath.FromSlash(g.Package))
+	}
+	if err := checkMainPackage(mainDir); err != nil {
+		return err
+	}
+
+	moduleRoot, pkg, err := g.resolvePackage(mainDir)
+	if err != nil {
+		return err
+	}
+
+	// El binario va en <outputPath>/bootstrap: outputPath debe ser un directorio
+	if info, err := os.Stat(outputPath); err == nil && !info.IsDir() {
+		return fmt.Errorf("output path %s exists and is not a directory", outputPath)
+	}
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
 		return fmt.Errorf("error creating output directory: %w", err)
 	}
 
+	// Se compila desde la raíz del módulo, igual que un go build manual
 	buildCmd := exec.Command("go", "build",
-		"-o", fmt.Sprintf("%s/bootstrap", outputPath),
+		"-o", filepath.Join(outputPath, "bootstrap"),
 		"-ldflags", "-s -w",
-		outputPath,
+		pkg,
 	)
-	buildCmd.Dir = functionDir
+	goarch := g.Arch
+	if goarch == "" {
+		goarch = "amd64"
+	}
+	buildCmd.Dir = moduleRoot
 	buildCmd.Env = append(os.Environ(),
 		"GOOS=linux",
-		"GOARCH=amd64",
+		"GOARCH="+goarch,
 		"CGO_ENABLED=0",
 	)
 
@@ -40,9 +83,13 @@ func (g *GolangRuntime) Build(functionDir string, outputPath string) error {
 	buildCmd.Stderr = &stderr
 
 	if err := buildCmd.Run(); err != nil {
-		log.Printf("🚨 Go build failed:")
-		log.Printf("STDERR: %s", stderr.String())
-		return fmt.Errorf("go build failed: %w", err)
+		buildErr := &BuildError{
+			Diagnostics: parseGoDiagnostics(stderr.String()),
+			Output:      stderr.String(),
+			Err:         err,
+		}
+		g.logBuildError(buildErr)
+		return buildErr
 	}
 
 	if stdout.Len() > 0 {
@@ -52,6 +99,137 @@ func (g *GolangRuntime) Build(functionDir string, outputPath string) error {
 	return nil
 }
 
+// checkMainPackage verifica que dir contenga un único paquete y que sea main
+func checkMainPackage(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	packages := map[string]bool{}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return fmt.Errorf("error reading package of %s: %w", file, err)
+		}
+		packages[f.Name.Name] = true
+	}
+
+	switch {
+	case len(packages) == 0:
+		return fmt.Errorf("no Go files found in %s (set handler to the main package directory relative to code)", dir)
+	case len(packages) > 1 || !packages["main"]:
+		names := make([]string, 0, len(packages))
+		for name := range packages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("expected a single main package in %s, found: %s", dir, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// CheckEntrypoint verifica que el paquete a compilar sea main.
+// El handler ya se tradujo a Package al inicializar el runtime.
+func (g *GolangRuntime) CheckEntrypoint(functionDir string, handler string) error {
+	mainDir := functionDir
+	if g.Package != "" {
+		mainDir = filepath.Join(functionDir, filepath.FromSlash(g.Package))
+	}
+	return checkMainPackage(mainDir)
+}
+
+// resolvePackage devuelve la raíz del módulo y el paquete del handler relativo
+// a ella (p. ej. "./cmd/handler")
+func (g *GolangRuntime) resolvePackage(functionDir string) (moduleRoot, pkg string, err error) {
+	if functionDir, err = filepath.Abs(functionDir); err != nil {
+		return "", "", err
+	}
+
+	if g.ModuleRoot != "" {
+		if moduleRoot, err = filepath.Abs(g.ModuleRoot); err != nil {
+			return "", "", err
+		}
+		if _, err := os.Stat(filepath.Join(moduleRoot, "go.mod")); err != nil {
+			return "", "", fmt.Errorf("%w in moduleRoot %s", ErrGoModNotFound, moduleRoot)
+		}
+	} else {
+		// Sin go.mod, go build falla con un error de módulos poco claro
+		goMod, err := FindGoMod(functionDir, g.RootPath)
+		if err != nil {
+			return "", "", err
+		}
+		moduleRoot = filepath.Dir(goMod)
+	}
+
+	rel, err := filepath.Rel(moduleRoot, functionDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", "", fmt.Errorf("function directory %s is outside moduleRoot %s", functionDir, moduleRoot)
+	}
+	return moduleRoot, "./" + filepath.ToSlash(rel), nil
+}
+
+// logBuildError muestra los diagnósticos agrupados por archivo; la salida
+// cruda solo en modo verbose o si no se pudo parsear nada
+func (g *GolangRuntime) logBuildError(e *BuildError) {
+	log.Printf("🚨 Go build failed:")
+
+	files, byFile := e.Grouped()
+	for _, file := range files {
+		log.Printf("  %s", file)
+		for _, d := range byFile[file] {
+			if d.Col > 0 {
+				log.Printf("    %d:%d: %s", d.Line, d.Col, d.Message)
+			} else {
+				log.Printf("    %d: %s", d.Line, d.Message)
+			}
+		}
+	}
+
+	if g.Verbose || len(e.Diagnostics) == 0 {
+		log.Printf("STDERR: %s", e.Output)
+	}
+}
+
+// FindGoMod busca go.mod desde dir hacia arriba sin salir de root, y devuelve
+// su ruta o ErrGoModNotFound con los directorios revisados
+func FindGoMod(dir, root string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if root != "" {
+		if root, err = filepath.Abs(root); err != nil {
+			return "", err
+		}
+	}
+
+	var searched []string
+	for {
+		searched = append(searched, dir)
+		goMod := filepath.Join(dir, "go.mod")
+		if info, err := os.Stat(goMod); err == nil && !info.IsDir() {
+			return goMod, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir {
+			break
+		}
+		if root != "" {
+			if rel, err := filepath.Rel(root, parent); err != nil || strings.HasPrefix(rel, "..") {
+				break
+			}
+		}
+		dir = parent
+	}
+
+	return "", fmt.Errorf("%w (searched: %s)", ErrGoModNotFound, strings.Join(searched, ", "))
+}
+
 func (g *GolangRuntime) WatchPatterns() []string {
 	return []string{"*.go", "go.mod", "go.sum"}
 }
@@ -63,3 +241,18 @@ func (g *GolangRuntime) NeedsBuild() bool {
 func (g *GolangRuntime) StartCommand(binaryPath string) []string {
 	return []string{binaryPath}
 }
+
+// TestCommand corre go test sobre el paquete del handler y sus subpaquetes,
+// desde la raíz del módulo como Build. Sin GOOS/GOARCH de Lambda: los
+// binarios de test se ejecutan en el host.
+func (g *GolangRuntime) TestCommand(functionDir string) (*exec.Cmd, error) {
+	moduleRoot, pkg, err := g.resolvePackage(functionDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("go", "test", strings.TrimSuffix(pkg, "/.")+"/...")
+	cmd.Dir = moduleRoot
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	return cmd, nil
+}
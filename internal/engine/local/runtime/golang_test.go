@@ -0,0 +1,107 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles crea los archivos (ruta relativa -> contenido) bajo dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const mainGo = "package main\n\nfunc main() {}\n"
+
+func TestFindGoMod(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                 "module example.com/app\n",
+		"functions/api/main.go":  mainGo,
+		"nested/project/main.go": mainGo,
+	})
+
+	goMod, err := FindGoMod(filepath.Join(root, "functions", "api"), root)
+	if err != nil {
+		t.Fatalf("FindGoMod: %v", err)
+	}
+	if goMod != filepath.Join(root, "go.mod") {
+		t.Errorf("FindGoMod = %s, want the go.mod at the root", goMod)
+	}
+
+	// La búsqueda no sale de root aunque haya un go.mod más arriba
+	project := filepath.Join(root, "nested", "project")
+	_, err = FindGoMod(project, project)
+	if !errors.Is(err, ErrGoModNotFound) {
+		t.Fatalf("FindGoMod outside the module = %v, want ErrGoModNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "searched: "+project) {
+		t.Errorf("error %q does not list the searched paths", err)
+	}
+}
+
+// Sin go.mod Build falla con un error claro antes de invocar go build
+func TestBuildWithoutGoMod(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"api/main.go": mainGo})
+	functionDir := filepath.Join(root, "api")
+
+	g := &GolangRuntime{RootPath: root}
+	err := g.Build(functionDir, filepath.Join(root, "out"))
+	if !errors.Is(err, ErrGoModNotFound) {
+		t.Fatalf("Build = %v, want ErrGoModNotFound", err)
+	}
+	if !strings.Contains(err.Error(), functionDir) || !strings.Contains(err.Error(), root) {
+		t.Errorf("error %q does not list the searched paths", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "out")); !os.IsNotExist(err) {
+		t.Errorf("Build created the output directory before failing")
+	}
+}
+
+// Build crea el directorio de salida completo, no solo su padre
+func TestBuildIntoFreshNestedDirectory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go build")
+	}
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.21\n",
+		"api/main.go": mainGo,
+	})
+	outputPath := filepath.Join(root, ".qriosls", "build", "api")
+
+	g := &GolangRuntime{RootPath: root}
+	if err := g.Build(filepath.Join(root, "api"), outputPath); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(outputPath, "bootstrap")); err != nil || info.IsDir() {
+		t.Fatalf("bootstrap not built in %s: %v", outputPath, err)
+	}
+}
+
+func TestBuildRejectsFileAsOutputPath(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.21\n",
+		"api/main.go": mainGo,
+		"out":         "",
+	})
+
+	g := &GolangRuntime{RootPath: root}
+	err := g.Build(filepath.Join(root, "api"), filepath.Join(root, "out"))
+	if err == nil || !strings.Contains(err.Error(), "is not a directory") {
+		t.Fatalf("Build = %v, want an error about the output path", err)
+	}
+}
@@ -1,24 +1,41 @@
 package runtime
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
-type NodeJSRuntime struct{}
+type NodeJSRuntime struct {
+	// SkipInstall omite npm install (dependencias ya vendorizadas u offline)
+	SkipInstall bool
+
+	// TypeScript indica que el código tiene tsconfig.json y se compila a JS
+	TypeScript bool
+
+	// Handler de la función ("src/index.handler"): esbuild parte de su .ts
+	Handler string
+
+	// Version mayor de Node según el runtime configurado (p. ej. "20"), target de esbuild
+	Version string
+}
 
 func (n *NodeJSRuntime) Name() string {
 	return "nodejs"
 }
 
 func (n *NodeJSRuntime) Build(functionDir string, outputPath string) error {
-	log.Printf("📦 Installing dependencies for Node.js function in: %s", functionDir)
+	if n.SkipInstall {
+		log.Printf("📦 Skipping npm install for Node.js function in: %s", functionDir)
+	} else if _, err := os.Stat(filepath.Join(functionDir, "package.json")); err == nil {
+		log.Printf("📦 Installing dependencies for Node.js function in: %s", functionDir)
 
-	// npm install o yarn install
-	if _, err := os.Stat(filepath.Join(functionDir, "package.json")); err == nil {
 		cmd := exec.Command("npm", "install")
 		cmd.Dir = functionDir
 
@@ -27,7 +44,97 @@ func (n *NodeJSRuntime) Build(functionDir string, outputPath string) error {
 		}
 	}
 
-	return nil
+	if !n.TypeScript {
+		return nil
+	}
+	return n.compileTypeScript(functionDir, outputPath)
+}
+
+// compileTypeScript genera el JS en outputPath: con esbuild (un bundle del
+// handler) o, si no está, con tsc y el tsconfig.json de la función.
+// Se compila a un directorio temporal y solo se copian los archivos que
+// cambian, para que el watcher no vuelva a disparar el build en bucle.
+func (n *NodeJSRuntime) compileTypeScript(functionDir string, outputPath string) error {
+	tmp, err := os.MkdirTemp("", "qriosls-ts-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	var cmd *exec.Cmd
+	if esbuild := findNodeTool(functionDir, "esbuild"); esbuild != "" {
+		module := handlerModule(n.Handler)
+		entry := filepath.FromSlash(module) + ".ts"
+		if _, err := os.Stat(filepath.Join(functionDir, entry)); err != nil {
+			return fmt.Errorf("handler '%s' has no TypeScript entrypoint: expected %s", n.Handler, filepath.Join(functionDir, entry))
+		}
+
+		// El SDK v3 de AWS ya viene en el runtime de Lambda
+		args := []string{entry, "--bundle", "--platform=node", "--format=cjs", "--external:@aws-sdk/*",
+			"--outfile=" + filepath.Join(tmp, filepath.FromSlash(module)+".js")}
+		if n.Version != "" {
+			args = append(args, "--target=node"+n.Version)
+		}
+		cmd = exec.Command(esbuild, args...)
+	} else if tsc := findNodeTool(functionDir, "tsc"); tsc != "" {
+		cmd = exec.Command(tsc, "-p", "tsconfig.json", "--outDir", tmp)
+	} else {
+		return fmt.Errorf("TypeScript function in %s needs esbuild or tsc: add one to devDependencies (npm install --save-dev esbuild) or to PATH", functionDir)
+	}
+
+	log.Printf("🔨 Compiling TypeScript function in: %s (%s)", functionDir, filepath.Base(cmd.Path))
+	cmd.Dir = functionDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w\nOutput: %s", filepath.Base(cmd.Path), err, string(output))
+	}
+
+	return copyChangedFiles(tmp, outputPath)
+}
+
+// findNodeTool busca un binario en node_modules/.bin de la función y luego en el PATH
+func findNodeTool(functionDir, name string) string {
+	local := filepath.Join(functionDir, "node_modules", ".bin", name)
+	if _, err := os.Stat(local); err == nil {
+		return local
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+	return ""
+}
+
+// copyChangedFiles copia a dst los archivos de src cuyo contenido difiere
+func copyChangedFiles(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+		if current, err := os.ReadFile(target); err == nil && bytes.Equal(current, data) {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+}
+
+// handlerModule devuelve el módulo del handler ("src/index.handler" -> "src/index")
+func handlerModule(handler string) string {
+	if i := strings.LastIndex(handler, "."); i > 0 {
+		return handler[:i]
+	}
+	return handler
 }
 
 func (n *NodeJSRuntime) WatchPatterns() []string {
@@ -35,9 +142,46 @@ func (n *NodeJSRuntime) WatchPatterns() []string {
 }
 
 func (n *NodeJSRuntime) NeedsBuild() bool {
-	return false // Node.js normalmente no necesita build (a menos que sea TypeScript)
+	return n.TypeScript // JS plano se ejecuta tal cual; TypeScript se compila
 }
 
 func (n *NodeJSRuntime) StartCommand(binaryPath string) []string {
 	return []string{"node", binaryPath}
 }
+
+// npm init deja este script cuando no hay tests
+const npmDefaultTestScript = "echo \"Error: no test specified\" && exit 1"
+
+// TestCommand corre npm test si package.json define un script de test
+func (n *NodeJSRuntime) TestCommand(functionDir string) (*exec.Cmd, error) {
+	b, err := os.ReadFile(filepath.Join(functionDir, "package.json"))
+	if err != nil {
+		return nil, nil
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return nil, fmt.Errorf("error parsing package.json: %w", err)
+	}
+	if script := pkg.Scripts["test"]; script == "" || script == npmDefaultTestScript {
+		return nil, nil
+	}
+
+	cmd := exec.Command("npm", "test")
+	cmd.Dir = functionDir
+	return cmd, nil
+}
+
+// CheckEntrypoint verifica que exista el archivo del handler ("src/index.handler"
+// -> src/index.js, .mjs, .cjs o .ts)
+func (n *NodeJSRuntime) CheckEntrypoint(functionDir string, handler string) error {
+	base := filepath.Join(functionDir, filepath.FromSlash(handlerModule(handler)))
+	for _, ext := range []string{".js", ".mjs", ".cjs", ".ts"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("handler '%s' has no entrypoint file: expected %s.js (or .mjs, .cjs, .ts)", handler, base)
+}
@@ -6,38 +6,151 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+
+	"github.com/qrioso-software/qriososls/internal/util"
 )
 
-type PythonRuntime struct{}
+type PythonRuntime struct {
+	// SkipInstall omite pip install (dependencias ya vendorizadas u offline)
+	SkipInstall bool
+
+	// Version esperada del intérprete según el runtime configurado (p. ej. "3.12")
+	Version string
+
+	// Requirements indica que code tiene requirements.txt: el build copia el
+	// código al build dir (el asset) e instala ahí las dependencias
+	Requirements bool
+}
 
 func (p *PythonRuntime) Name() string {
 	return "python"
 }
 
 func (p *PythonRuntime) Build(functionDir string, outputPath string) error {
-	log.Printf("🐍 Installing dependencies for Python function in: %s", functionDir)
+	requirements := filepath.Join(functionDir, "requirements.txt")
+	if _, err := os.Stat(requirements); err != nil {
+		return nil
+	}
+
+	// Nunca instalar dentro del código fuente: el build dir es el asset
+	if filepath.Clean(outputPath) == filepath.Clean(functionDir) {
+		return fmt.Errorf("refusing to install Python dependencies into the source directory %s", functionDir)
+	}
+
+	// Copia limpia del código: archivos borrados del fuente no quedan en el asset
+	if err := os.RemoveAll(outputPath); err != nil {
+		return fmt.Errorf("error cleaning Python build directory: %w", err)
+	}
+	if err := util.CopyDir(functionDir, outputPath); err != nil {
+		return fmt.Errorf("error copying Python sources: %w", err)
+	}
 
-	// pip install si hay requirements.txt
-	if _, err := os.Stat(filepath.Join(functionDir, "requirements.txt")); err == nil {
-		cmd := exec.Command("pip", "install", "-r", "requirements.txt", "-t", functionDir)
-		cmd.Dir = functionDir
+	// Dependencias ya vendorizadas en code: la copia ya las incluye
+	if p.SkipInstall {
+		log.Printf("🐍 Skipping pip install for Python function in: %s", functionDir)
+		return nil
+	}
 
-		if output, err := cmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("pip install failed: %w\nOutput: %s", err, string(output))
-		}
+	python, err := p.interpreter()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🐍 Installing dependencies for Python function in: %s → %s", functionDir, outputPath)
+
+	cmd := exec.Command(python, "-m", "pip", "install", "-r", requirements, "-t", outputPath)
+	cmd.Dir = functionDir
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("pip install failed: %w\nOutput: %s", err, string(output))
 	}
 
 	return nil
 }
 
+// interpreter busca python3/python y verifica que su versión coincida con el runtime
+func (p *PythonRuntime) interpreter() (string, error) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		if python, err = exec.LookPath("python"); err != nil {
+			return "", fmt.Errorf("python interpreter not found in PATH")
+		}
+	}
+
+	if p.Version == "" {
+		return python, nil
+	}
+
+	out, err := exec.Command(python, "--version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error checking python version: %w", err)
+	}
+
+	// "Python 3.12.1" -> "3.12.1"
+	found := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(out)), "Python"))
+	if found != p.Version && !strings.HasPrefix(found, p.Version+".") {
+		return "", fmt.Errorf("python %s found but runtime expects python%s", found, p.Version)
+	}
+
+	return python, nil
+}
+
 func (p *PythonRuntime) WatchPatterns() []string {
 	return []string{"*.py", "requirements.txt"}
 }
 
 func (p *PythonRuntime) NeedsBuild() bool {
-	return false
+	return p.Requirements // Sin dependencias el código se empaqueta tal cual
 }
 
 func (p *PythonRuntime) StartCommand(binaryPath string) []string {
 	return []string{"python", binaryPath}
 }
+
+// TestCommand corre pytest si la función tiene configuración o archivos de test
+func (p *PythonRuntime) TestCommand(functionDir string) (*exec.Cmd, error) {
+	if !hasPythonTests(functionDir) {
+		return nil, nil
+	}
+
+	python, err := p.interpreter()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(python, "-m", "pytest")
+	cmd.Dir = functionDir
+	return cmd, nil
+}
+
+func hasPythonTests(dir string) bool {
+	for _, name := range []string{"pytest.ini", "conftest.py", "tests"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	for _, pattern := range []string{"test_*.py", "*_test.py"} {
+		if files, _ := filepath.Glob(filepath.Join(dir, pattern)); len(files) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEntrypoint verifica que exista el módulo del handler ("pkg.app.handler"
+// -> pkg/app.py o pkg/app/__init__.py)
+func (p *PythonRuntime) CheckEntrypoint(functionDir string, handler string) error {
+	module := handler
+	if i := strings.LastIndex(handler, "."); i > 0 {
+		module = handler[:i]
+	}
+
+	base := filepath.Join(functionDir, filepath.FromSlash(strings.ReplaceAll(module, ".", "/")))
+	for _, candidate := range []string{base + ".py", filepath.Join(base, "__init__.py")} {
+		if _, err := os.Stat(candidate); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("handler '%s' has no entrypoint module: expected %s.py", handler, base)
+}
@@ -1,5 +1,7 @@
 package runtime
 
+import "os/exec"
+
 // Runtime define la interface que todos los runtimes deben implementar
 type Runtime interface {
 	// Name retorna el nombre del runtime
@@ -16,6 +18,14 @@ type Runtime interface {
 
 	// StartCommand retorna el comando para ejecutar localmente
 	StartCommand(binaryPath string) []string
+
+	// TestCommand retorna el comando que corre los tests de la función, o nil
+	// si la función no tiene tests configurados
+	TestCommand(functionDir string) (*exec.Cmd, error)
+
+	// CheckEntrypoint verifica que functionDir tenga algo ejecutable para el
+	// handler antes de compilar (paquete main, archivo .js o módulo .py)
+	CheckEntrypoint(functionDir string, handler string) error
 }
 
 // FunctionConfig configuración para una función
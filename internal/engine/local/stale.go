@@ -0,0 +1,70 @@
+// internal/engine/local/stale.go
+package local
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// isConfigFile reports whether path is one of the config files the stack was synthesized from
+func (lr *LocalRunner) isConfigFile(path string) bool {
+	for _, file := range lr.opts.ConfigFiles {
+		if filepath.Clean(path) == lr.absPath(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleConfigChange reacts to an edited config file: the running API still
+// serves the routes of the old template until it is synthesized again
+func (lr *LocalRunner) handleConfigChange(path string) {
+	if lr.opts.AutoSynth {
+		log.Printf("📝 %s changed, re-synthesizing", filepath.Base(path))
+		lr.Reload()
+		return
+	}
+	if !lr.configStale {
+		lr.configStale = true
+		log.Printf("⚠️ %s changed: the local API keeps serving the previous routes. Press r + Enter to reload, or run with --auto-synth", filepath.Base(path))
+	}
+}
+
+// staleTemplateReason returns why the synthesized template is older than
+// its inputs (config files or function sources), or "" when it is current
+func (lr *LocalRunner) staleTemplateReason() string {
+	info, err := os.Stat(lr.synth.TemplatePath)
+	if err != nil {
+		return fmt.Sprintf("%s does not exist", lr.synth.TemplatePath)
+	}
+	synthesized := info.ModTime()
+
+	for _, file := range lr.opts.ConfigFiles {
+		if newerThan(lr.absPath(file), synthesized) {
+			return fmt.Sprintf("%s changed after the template was synthesized", file)
+		}
+	}
+
+	active := lr.sourceFunctions()
+	for funcName, function := range active {
+		codePath := lr.absPath(function.Code)
+		entries, err := os.ReadDir(codePath)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && newerThan(filepath.Join(codePath, entry.Name()), synthesized) {
+				return fmt.Sprintf("sources of %s changed after the template was synthesized", funcName)
+			}
+		}
+	}
+	return ""
+}
+
+func newerThan(path string, t time.Time) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.ModTime().After(t)
+}
@@ -0,0 +1,63 @@
+// internal/engine/local/test.go
+package local
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+	"github.com/qrioso-software/qriososls/internal/progress"
+)
+
+// TestResult is the outcome of one function's test suite
+type TestResult struct {
+	Function string
+	Runtime  string
+	Skipped  bool  // No tests configured for the function
+	Err      error // Nil when the tests passed
+}
+
+// RunTests runs each function's tests through its runtime, in a stable order.
+// A failing suite doesn't stop the others; the caller decides the exit code.
+func (lr *LocalRunner) RunTests() ([]TestResult, error) {
+	if err := lr.initializeRuntimes(); err != nil {
+		return nil, err
+	}
+
+	active := lr.sourceFunctions()
+	results := make([]TestResult, 0, len(active))
+	for _, funcName := range config.SortedFunctionNames(active) {
+		rt := lr.functionRuntimes[funcName]
+		result := TestResult{Function: funcName, Runtime: rt.Name()}
+
+		cmd, err := rt.TestCommand(lr.testDir(active[funcName]))
+		switch {
+		case err != nil:
+			result.Err = err
+		case cmd == nil:
+			result.Skipped = true
+			log.Printf("⏭️ No tests configured for %s (runtime: %s)", funcName, rt.Name())
+		default:
+			cmd.Stdout = progress.Stdout()
+			cmd.Stderr = os.Stderr
+			log.Printf("🧪 Testing %s (%s)", funcName, rt.Name())
+			if err := progress.Start("test", funcName).Done(cmd.Run()); err != nil {
+				result.Err = fmt.Errorf("tests failed: %w", err)
+			}
+		}
+
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// testDir is the directory holding the function sources (code may point at a file)
+func (lr *LocalRunner) testDir(function config.LambdaFunc) string {
+	codePath := lr.absPath(function.Code)
+	if dirExists(codePath) {
+		return codePath
+	}
+	return filepath.Dir(codePath)
+}
@@ -0,0 +1,197 @@
+// internal/engine/local/trace.go
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+)
+
+// defaultTraceBodyLimit bounds how many bytes of each body are logged
+const defaultTraceBodyLimit = 4096
+
+// requestIDHeader correlates a proxied request with the Lambda invocation it triggers
+const requestIDHeader = "X-Request-Id"
+
+// TraceProxy is a thin logging reverse proxy placed in front of the SAM endpoint
+type TraceProxy struct {
+	server    *http.Server
+	logBodies bool
+	bodyLimit int
+}
+
+// NewTraceProxy creates a proxy listening on listenAddr that forwards to target
+func NewTraceProxy(listenAddr, target string, logBodies bool, bodyLimit int) (*TraceProxy, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace target %s: %w", target, err)
+	}
+
+	if bodyLimit <= 0 {
+		bodyLimit = defaultTraceBodyLimit
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	proxy.FlushInterval = -1 // Flush immediately to preserve streaming responses
+
+	tp := &TraceProxy{
+		logBodies: logBodies,
+		bodyLimit: bodyLimit,
+	}
+	tp.server = &http.Server{
+		Addr:    listenAddr,
+		Handler: tp.wrap(proxy),
+	}
+	return tp, nil
+}
+
+// Start begins serving in the background
+func (tp *TraceProxy) Start() error {
+	ln, err := net.Listen("tcp", tp.server.Addr)
+	if err != nil {
+		return fmt.Errorf("error starting trace proxy: %w", err)
+	}
+
+	go func() {
+		if err := tp.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("❌ Trace proxy error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts the proxy down, waiting briefly for in-flight requests
+func (tp *TraceProxy) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	tp.server.Shutdown(ctx)
+}
+
+// wrap logs method, path, status and duration for every proxied request.
+// Each request carries an X-Request-Id (the client's, or a generated one) that
+// reaches the Lambda event headers and is echoed back in the response.
+func (tp *TraceProxy) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+			r.Header.Set(requestIDHeader, requestID)
+		}
+
+		var reqBody *limitedBuffer
+		if tp.logBodies && r.Body != nil {
+			reqBody = &limitedBuffer{limit: tp.bodyLimit}
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(r.Body, reqBody), r.Body}
+		}
+
+		rec := &traceWriter{ResponseWriter: w, status: http.StatusOK, requestID: requestID}
+		if tp.logBodies {
+			rec.body = &limitedBuffer{limit: tp.bodyLimit}
+		}
+
+		next.ServeHTTP(rec, r)
+
+		log.Printf("🔎 [%s] %s %s → %d (%s, %d bytes)",
+			requestID, r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Millisecond), rec.bytes)
+
+		if reqBody != nil && reqBody.Len() > 0 {
+			log.Printf("   request body: %s", reqBody)
+		}
+		if rec.body != nil && rec.body.Len() > 0 {
+			log.Printf("   response body: %s", rec.body)
+		}
+	})
+}
+
+// newRequestID returns a random 128-bit hex id
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// traceWriter records the status code and size while keeping streaming intact
+type traceWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	body        *limitedBuffer
+	requestID   string
+	wroteHeader bool
+}
+
+// WriteHeader echoes the request id, replacing any the upstream already set
+func (tw *traceWriter) WriteHeader(status int) {
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		tw.Header().Set(requestIDHeader, tw.requestID)
+	}
+	tw.status = status
+	tw.ResponseWriter.WriteHeader(status)
+}
+
+func (tw *traceWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.body != nil {
+		tw.body.Write(b)
+	}
+	n, err := tw.ResponseWriter.Write(b)
+	tw.bytes += n
+	return n, err
+}
+
+func (tw *traceWriter) Flush() {
+	if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// limitedBuffer keeps only the first limit bytes written to it
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	if room := lb.limit - lb.buf.Len(); room > 0 {
+		if len(p) > room {
+			lb.buf.Write(p[:room])
+			lb.truncated = true
+		} else {
+			lb.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		lb.truncated = true
+	}
+	return len(p), nil
+}
+
+func (lb *limitedBuffer) Len() int {
+	return lb.buf.Len()
+}
+
+func (lb *limitedBuffer) String() string {
+	if lb.truncated {
+		return lb.buf.String() + "…(truncated)"
+	}
+	return lb.buf.String()
+}
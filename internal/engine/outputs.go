@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsapigateway"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// Output con la URL de invocación del API (nombre estable, a diferencia del Endpoint de CDK)
+const OutputApiUrl = "ApiUrl"
+
+// Sufijo de los outputs con el ARN de cada función: <logicalName>FunctionArn
+const OutputFunctionArnSuffix = "FunctionArn"
+
+func addApiUrlOutput(scope constructs.Construct, api awsapigateway.RestApi) {
+	awscdk.NewCfnOutput(scope, jsii.String(OutputApiUrl), &awscdk.CfnOutputProps{
+		Value:       api.Url(),
+		Description: jsii.String("Invoke URL of the API stage"),
+	})
+}
+
+func addFunctionArnOutput(scope constructs.Construct, logicalName string, lambdaFn awslambda.Function, functionName string) {
+	awscdk.NewCfnOutput(scope, jsii.String(logicalName+OutputFunctionArnSuffix), &awscdk.CfnOutputProps{
+		Value:       lambdaFn.FunctionArn(),
+		Description: jsii.String("ARN of function " + functionName),
+	})
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+	"github.com/qrioso-software/qriososls/internal/util"
+)
+
+// PolicyDocument es una política IAM en su forma JSON
+type PolicyDocument struct {
+	Version   string            `json:"Version"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+// PolicyStatement es un statement Allow de PolicyDocument
+type PolicyStatement struct {
+	Sid      string   `json:"Sid"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+// RequiredPermissions calcula la política mínima para desplegar cfg con el
+// CDK CLI: solo incluye los servicios que la configuración usa de verdad
+func RequiredPermissions(cfg *config.ServerlessConfig) PolicyDocument {
+	stackName := cfg.StackName()
+	active := cfg.ActiveFunctions()
+
+	statements := []PolicyStatement{
+		{
+			Sid: "CloudFormationStack",
+			Action: []string{"cloudformation:CreateChangeSet", "cloudformation:DeleteChangeSet", "cloudformation:DescribeChangeSet",
+				"cloudformation:DescribeStackEvents", "cloudformation:DescribeStacks", "cloudformation:ExecuteChangeSet",
+				"cloudformation:GetTemplate"},
+			Resource: []string{"arn:aws:cloudformation:*:*:stack/" + stackName + "/*"},
+		},
+		{
+			// Assets de Lambda: el synthesizer legacy los sube al bucket de CDKToolkit
+			Sid:      "CdkToolkitAssets",
+			Action:   []string{"cloudformation:DescribeStacks", "s3:GetBucketLocation", "s3:GetObject", "s3:ListBucket", "s3:PutObject"},
+			Resource: []string{"arn:aws:cloudformation:*:*:stack/CDKToolkit/*", "arn:aws:s3:::cdk-*", "arn:aws:s3:::cdktoolkit-stagingbucket-*"},
+		},
+	}
+
+	var functionArns, passRoles, kmsKeys, layers []string
+	createsRoles, usesVpc, usesDestinations, createsBuckets := false, false, false, false
+	eventTypes := map[string][]string{}
+	for _, funcName := range config.SortedFunctionNames(active) {
+		fn := active[funcName]
+		functionArns = append(functionArns, "arn:aws:lambda:*:*:function:"+util.ResolveVars(fn.FunctionName, cfg.Stage))
+
+		if role := fn.ExecutionRole(); role != "" {
+			passRoles = append(passRoles, role)
+		} else {
+			createsRoles = true
+		}
+		layers = append(layers, fn.Layers...)
+		if fn.KmsKeyArn != "" {
+			kmsKeys = append(kmsKeys, fn.KmsKeyArn)
+		}
+		if fn.Vpc != nil {
+			usesVpc = true
+		}
+		if fn.Destinations != nil {
+			usesDestinations = true
+		}
+
+		for _, ev := range fn.Events {
+			eventType := strings.ToLower(ev.Type)
+			if eventType == "http" {
+				continue
+			}
+			source := "*"
+			if arn := ev.QueueArn(); strings.HasPrefix(arn, "arn:") {
+				source = arn
+			}
+			if ev.Bucket != "" {
+				source = "arn:aws:s3:::" + ev.Bucket
+				createsBuckets = createsBuckets || !ev.Existing
+			}
+			eventTypes[eventType] = append(eventTypes[eventType], source)
+		}
+	}
+
+	if len(functionArns) > 0 {
+		actions := []string{"lambda:AddPermission", "lambda:CreateFunction", "lambda:DeleteFunction", "lambda:GetFunction",
+			"lambda:GetFunctionConfiguration", "lambda:PutRuntimeManagementConfig", "lambda:RemovePermission",
+			"lambda:TagResource", "lambda:UntagResource", "lambda:UpdateFunctionCode", "lambda:UpdateFunctionConfiguration"}
+		if usesDestinations {
+			actions = append(actions, "lambda:DeleteFunctionEventInvokeConfig", "lambda:GetFunctionEventInvokeConfig",
+				"lambda:PutFunctionEventInvokeConfig", "lambda:UpdateFunctionEventInvokeConfig")
+		}
+		statements = append(statements, PolicyStatement{
+			Sid:      "LambdaFunctions",
+			Action:   actions,
+			Resource: functionArns,
+		})
+	}
+
+	// Roles de ejecución creados por CDK: <stack>-<función>Role...
+	if createsRoles {
+		statements = append(statements, PolicyStatement{
+			Sid: "ExecutionRoles",
+			Action: []string{"iam:AttachRolePolicy", "iam:CreateRole", "iam:DeleteRole", "iam:DeleteRolePolicy",
+				"iam:DetachRolePolicy", "iam:GetRole", "iam:PassRole", "iam:PutRolePolicy", "iam:TagRole"},
+			Resource: []string{"arn:aws:iam::*:role/" + stackName + "-*"},
+		})
+	}
+	if len(passRoles) > 0 {
+		statements = append(statements, PolicyStatement{
+			Sid:      "ExistingExecutionRoles",
+			Action:   []string{"iam:PassRole"},
+			Resource: uniqueSorted(passRoles),
+		})
+	}
+
+	// El stack siempre crea el API, aunque no haya rutas
+	apiActions := []string{"apigateway:DELETE", "apigateway:GET", "apigateway:PATCH", "apigateway:POST", "apigateway:PUT"}
+	if cfg.Api != nil && cfg.Api.ResourcePolicy != nil {
+		apiActions = append(apiActions, "apigateway:UpdateRestApiPolicy")
+	}
+	apiResources := []string{"arn:aws:apigateway:*::/restapis", "arn:aws:apigateway:*::/restapis/*"}
+	if cfg.Api != nil && cfg.Api.Domain != nil {
+		apiResources = append(apiResources, "arn:aws:apigateway:*::/domainnames", "arn:aws:apigateway:*::/domainnames/*")
+	}
+	statements = append(statements, PolicyStatement{
+		Sid:      "ApiGateway",
+		Action:   apiActions,
+		Resource: apiResources,
+	})
+	if cfg.Api != nil && cfg.Api.Domain != nil {
+		statements = append(statements, PolicyStatement{
+			Sid:      "ApiDomainCertificate",
+			Action:   []string{"acm:DescribeCertificate"},
+			Resource: []string{cfg.Api.Domain.CertificateArn},
+		})
+	}
+
+	if usesVpc {
+		statements = append(statements, PolicyStatement{
+			Sid:      "LambdaVpc",
+			Action:   []string{"ec2:DescribeNetworkInterfaces", "ec2:DescribeSecurityGroups", "ec2:DescribeSubnets", "ec2:DescribeVpcs"},
+			Resource: []string{"*"},
+		})
+	}
+
+	if len(kmsKeys) > 0 {
+		statements = append(statements, PolicyStatement{
+			Sid:      "EnvironmentEncryption",
+			Action:   []string{"kms:CreateGrant", "kms:Decrypt", "kms:DescribeKey", "kms:Encrypt"},
+			Resource: uniqueSorted(kmsKeys),
+		})
+	}
+
+	if len(layers) > 0 {
+		statements = append(statements, PolicyStatement{
+			Sid:      "LambdaLayers",
+			Action:   []string{"lambda:GetLayerVersion"},
+			Resource: uniqueSorted(layers),
+		})
+	}
+
+	eventActions := map[string][]string{
+		"sqs": {"lambda:CreateEventSourceMapping", "lambda:DeleteEventSourceMapping", "lambda:GetEventSourceMapping", "sqs:GetQueueAttributes"},
+		"sns": {"sns:GetTopicAttributes", "sns:Subscribe", "sns:Unsubscribe"},
+		"s3":  {"s3:GetBucketNotification", "s3:PutBucketNotification"},
+		"schedule": {"events:DeleteRule", "events:DescribeRule", "events:PutRule", "events:PutTargets",
+			"events:RemoveTargets"},
+	}
+	// Buckets creados por el stack (s3 sin existing)
+	if createsBuckets {
+		eventActions["s3"] = append(eventActions["s3"], "s3:CreateBucket", "s3:DeleteBucket", "s3:PutEncryptionConfiguration")
+	}
+	for _, eventType := range sortedKeys(eventTypes) {
+		actions, ok := eventActions[eventType]
+		if !ok {
+			continue
+		}
+		statements = append(statements, PolicyStatement{
+			Sid:      strings.ToUpper(eventType) + "Events",
+			Action:   actions,
+			Resource: uniqueSorted(eventTypes[eventType]),
+		})
+	}
+
+	for i := range statements {
+		statements[i].Effect = "Allow"
+	}
+	return PolicyDocument{Version: "2012-10-17", Statement: statements}
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,34 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// ToolVersion es la versión de qriosls que se registra en el stack (la fija main)
+var ToolVersion = "dev"
+
+// Nombres de los outputs con la procedencia del stack
+const (
+	OutputConfigHash  = "QriososlsConfigHash"
+	OutputToolVersion = "QriososlsVersion"
+)
+
+// addProvenance marca el stack con el hash de la config de origen y la versión
+// de qriosls, para saber qué config produjo lo que está desplegado
+func addProvenance(stack awscdk.Stack, cfg *config.ServerlessConfig) {
+	stack.TemplateOptions().SetDescription(jsii.String(
+		fmt.Sprintf("%s (qriosls %s, config sha256:%s)", cfg.StackName(), ToolVersion, cfg.SourceHash)))
+
+	awscdk.NewCfnOutput(stack, jsii.String(OutputConfigHash), &awscdk.CfnOutputProps{
+		Value:       jsii.String(cfg.SourceHash),
+		Description: jsii.String("sha256 of the source config files"),
+	})
+	awscdk.NewCfnOutput(stack, jsii.String(OutputToolVersion), &awscdk.CfnOutputProps{
+		Value:       jsii.String(ToolVersion),
+		Description: jsii.String("qriosls version that synthesized the stack"),
+	})
+}
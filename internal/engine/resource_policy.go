@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsiam"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// Construye la resource policy del API: permite todo y luego deniega lo que
+// no cumpla allow (o cumpla deny). Devuelve nil si no hay política configurada.
+func resourcePolicy(rp *config.ResourcePolicyConfig) awsiam.PolicyDocument {
+	if rp == nil || (rp.Allow == nil && rp.Deny == nil) {
+		return nil
+	}
+
+	invoke := func(effect awsiam.Effect, conditions map[string]interface{}) awsiam.PolicyStatement {
+		props := &awsiam.PolicyStatementProps{
+			Effect:     effect,
+			Principals: &[]awsiam.IPrincipal{awsiam.NewAnyPrincipal()},
+			Actions:    jsii.Strings("execute-api:Invoke"),
+			Resources:  jsii.Strings("execute-api:/*"),
+		}
+		if conditions != nil {
+			props.Conditions = &conditions
+		}
+		return awsiam.NewPolicyStatement(props)
+	}
+
+	statements := []awsiam.PolicyStatement{invoke(awsiam.Effect_ALLOW, nil)}
+
+	// allow: se deniega cuando la petición no viene de ninguna fuente permitida
+	// (las condiciones de un mismo statement se evalúan con AND)
+	if rp.Allow != nil {
+		conditions := map[string]interface{}{}
+		if len(rp.Allow.SourceIps) > 0 {
+			conditions["NotIpAddress"] = map[string]interface{}{"aws:SourceIp": rp.Allow.SourceIps}
+		}
+		if len(rp.Allow.VpcEndpoints) > 0 {
+			conditions["StringNotEquals"] = map[string]interface{}{"aws:SourceVpce": rp.Allow.VpcEndpoints}
+		}
+		statements = append(statements, invoke(awsiam.Effect_DENY, conditions))
+	}
+
+	// deny: cada tipo de fuente en su propio statement (OR)
+	if rp.Deny != nil {
+		if len(rp.Deny.SourceIps) > 0 {
+			statements = append(statements, invoke(awsiam.Effect_DENY, map[string]interface{}{
+				"IpAddress": map[string]interface{}{"aws:SourceIp": rp.Deny.SourceIps},
+			}))
+		}
+		if len(rp.Deny.VpcEndpoints) > 0 {
+			statements = append(statements, invoke(awsiam.Effect_DENY, map[string]interface{}{
+				"StringEquals": map[string]interface{}{"aws:SourceVpce": rp.Deny.VpcEndpoints},
+			}))
+		}
+	}
+
+	return awsiam.NewPolicyDocument(&awsiam.PolicyDocumentProps{
+		Statements: &statements,
+	})
+}
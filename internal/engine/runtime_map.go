@@ -1,45 +1,62 @@
 package engine
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/qrioso-software/qriososls/internal/config"
 )
 
-func toLambdaRuntime(s string) awslambda.Runtime {
-	key := strings.ToLower(strings.TrimSpace(s))
-	key = strings.ReplaceAll(key, "_", "")
-	key = strings.ReplaceAll(key, "-", "")
-	key = strings.ReplaceAll(key, " ", "")
+// CheckRuntimes verifica que cada función activa tenga un runtime con mapeo a
+// CDK antes de crear el stack: synth devuelve este error en lugar de pasar un
+// runtime nil a NewFunction
+func CheckRuntimes(cfg *config.ServerlessConfig) error {
+	active := cfg.ActiveFunctions()
+	for _, funcName := range config.SortedFunctionNames(active) {
+		if toLambdaRuntime(active[funcName].Runtime) == nil {
+			return unmappedRuntimeError(active[funcName].Runtime, funcName)
+		}
+	}
+	return nil
+}
 
-	switch key {
-	case "nodejs20.x", "nodejs20x", "nodejs20":
+func unmappedRuntimeError(runtime, funcName string) error {
+	return fmt.Errorf("runtime '%s' of function '%s' is not supported (supported: %s)",
+		runtime, funcName, strings.Join(config.SupportedRuntimes(), ", "))
+}
+
+// toLambdaRuntime traduce el runtime (o su alias) al de CDK; nil si no hay mapeo.
+// Cubre los mismos nombres canónicos que config.SupportedRuntimes.
+func toLambdaRuntime(s string) awslambda.Runtime {
+	switch config.CanonicalRuntime(s) {
+	case "nodejs20.x":
 		return awslambda.Runtime_NODEJS_20_X()
-	case "nodejs18.x", "nodejs18x", "nodejs18":
+	case "nodejs18.x":
 		return awslambda.Runtime_NODEJS_18_X()
-	case "python3.10", "python310":
+	case "python3.10":
 		return awslambda.Runtime_PYTHON_3_10()
-	case "python3.12", "python312":
+	case "python3.12":
 		return awslambda.Runtime_PYTHON_3_12()
-	case "python3.11", "python311":
+	case "python3.11":
 		return awslambda.Runtime_PYTHON_3_11()
-	case "python3.9", "python39":
+	case "python3.9":
 		return awslambda.Runtime_PYTHON_3_9()
-	case "python3.8", "python38":
+	case "python3.8":
 		return awslambda.Runtime_PYTHON_3_8()
-	// case "go1.x", "go1x", "go":
-	// 	return awslambda.Runtime_GO_1_X()
 	case "java17":
 		return awslambda.Runtime_JAVA_17()
-	case "dotnet8", "dotnet8.0", "dotnet80", "dotnetcore8":
+	case "dotnet8":
 		return awslambda.Runtime_DOTNET_8()
-	case "ruby3.2", "ruby32":
+	case "ruby3.2":
 		return awslambda.Runtime_RUBY_3_2()
-	case "provided.al2", "providedal2", "provided", "go1.x", "go1x", "go":
+	// Runtimes custom, también los de Go: go y go1.x llegan aquí como provided.al2
+	// (el runtime gestionado go1.x ya no existe en Lambda) y el build genera el
+	// ejecutable bootstrap en la raíz del code
+	case "provided.al2":
 		return awslambda.Runtime_PROVIDED_AL2()
-	// Si tu versión de CDK lo trae, puedes habilitar:
-	// case "provided.al2023", "providedal2023":
-	// 	return awslambda.Runtime_PROVIDED_AL2023()
+	case "provided.al2023":
+		return awslambda.Runtime_PROVIDED_AL2023()
 	default:
 		return nil
 	}
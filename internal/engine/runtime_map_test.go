@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+func runtimeConfig(runtime string) *config.ServerlessConfig {
+	return &config.ServerlessConfig{
+		Service: "svc",
+		Stage:   "dev",
+		Functions: map[string]config.LambdaFunc{
+			"worker": {FunctionName: "worker", Runtime: runtime, Handler: "index.handler", Code: "src"},
+		},
+	}
+}
+
+func TestCheckRuntimes(t *testing.T) {
+	tests := []struct {
+		runtime string
+		wantErr bool
+	}{
+		{"nodejs20.x", false},
+		{"python3.12", false},
+		{"go", false},
+		{"go1.x", false},
+		{"provided.al2023", false},
+		{"node16", true},
+		{"cobol", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.runtime, func(t *testing.T) {
+			err := CheckRuntimes(runtimeConfig(tt.runtime))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckRuntimes(%q) error = %v, wantErr %v", tt.runtime, err, tt.wantErr)
+			}
+			if err != nil && (!strings.Contains(err.Error(), "'"+tt.runtime+"'") || !strings.Contains(err.Error(), "'worker'")) {
+				t.Errorf("error %q should name the runtime and the function", err)
+			}
+		})
+	}
+}
+
+// Cada alias de Go y de runtime custom llega al runtime de Lambda esperado
+func TestToLambdaRuntimeAliases(t *testing.T) {
+	tests := map[string]string{
+		"go":              "provided.al2",
+		"go1.x":           "provided.al2",
+		"provided":        "provided.al2",
+		"provided.al2":    "provided.al2",
+		"provided.al2023": "provided.al2023",
+		"nodejs20.x":      "nodejs20.x",
+	}
+	for runtime, want := range tests {
+		got := toLambdaRuntime(runtime)
+		if got == nil {
+			t.Errorf("toLambdaRuntime(%q) = nil, want %s", runtime, want)
+			continue
+		}
+		if name := *got.Name(); name != want {
+			t.Errorf("toLambdaRuntime(%q) = %s, want %s", runtime, name, want)
+		}
+	}
+}
+
+// Synth devuelve el error de runtime antes de arrancar jsii, sin panic
+func TestSynthUnknownRuntimeReturnsError(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("Synth panicked: %v", r)
+		}
+	}()
+
+	_, err := Synth(runtimeConfig("node16"), t.TempDir())
+	if err == nil {
+		t.Fatal("Synth with runtime node16 returned no error")
+	}
+	if !strings.Contains(err.Error(), "runtime 'node16' of function 'worker' is not supported") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// Sin Node.js Synth devuelve ErrNodeRequired antes de que CheckRuntimes llame a jsii
+func TestSynthWithoutNodeReturnsError(t *testing.T) {
+	t.Setenv("JSII_NODE", filepath.Join(t.TempDir(), "node"))
+
+	_, err := Synth(runtimeConfig("nodejs20.x"), t.TempDir())
+	if !errors.Is(err, ErrNodeRequired) {
+		t.Fatalf("Synth without Node.js = %v, want ErrNodeRequired", err)
+	}
+}
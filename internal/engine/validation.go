@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsapigateway"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/qrioso-software/qriososls/internal/config"
+)
+
+// requestValidators crea un RequestValidator por modo usado (params, all) y
+// lo reparte entre los métodos: api.requestValidation por defecto y
+// requestValidation del evento para relajarlo o endurecerlo.
+// all valida el body solo en métodos con request model (requestSchema).
+type requestValidators struct {
+	scope       constructs.Construct
+	api         awsapigateway.IRestApi
+	defaultMode string
+	byMode      map[string]awsapigateway.IRequestValidator
+	models      map[string]awsapigateway.IModel // por hash del schema: esquemas iguales comparten modelo
+}
+
+func newRequestValidators(scope constructs.Construct, api awsapigateway.IRestApi, apiCfg *config.ApiConfig) *requestValidators {
+	v := &requestValidators{scope: scope, api: api, byMode: make(map[string]awsapigateway.IRequestValidator),
+		models: make(map[string]awsapigateway.IModel)}
+	if apiCfg != nil {
+		v.defaultMode = apiCfg.RequestValidation
+	}
+	return v
+}
+
+// forEvent devuelve el validator del evento, o nil si no se valida
+func (v *requestValidators) forEvent(ev config.LambdaEvent) awsapigateway.IRequestValidator {
+	mode := ev.RequestValidation
+	if mode == "" {
+		mode = v.defaultMode
+	}
+	if mode == "" {
+		mode = ev.ImpliedRequestValidation()
+	}
+	if mode == "" || mode == config.RequestValidationNone {
+		return nil
+	}
+
+	if validator, ok := v.byMode[mode]; ok {
+		return validator
+	}
+	validator := awsapigateway.NewRequestValidator(v.scope, jsii.String("RequestValidator-"+mode), &awsapigateway.RequestValidatorProps{
+		RestApi:                   v.api,
+		ValidateRequestParameters: jsii.Bool(true),
+		ValidateRequestBody:       jsii.Bool(mode == config.RequestValidationAll),
+	})
+	v.byMode[mode] = validator
+	return validator
+}
+
+// requestModels devuelve los request models del método a partir de
+// requestSchema, o nil si el evento no define schema. Config.Validate ya
+// comprobó el schema, así que un error aquí se reporta como error de synth.
+func (v *requestValidators) requestModels(ev config.LambdaEvent) *map[string]awsapigateway.IModel {
+	schema, err := ev.RequestSchemaJSON()
+	if err != nil {
+		panic(err.Error())
+	}
+	if schema == nil {
+		return nil
+	}
+
+	sum := sha256.Sum256(schema)
+	hash := hex.EncodeToString(sum[:])
+	model, ok := v.models[hash]
+	if !ok {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(schema, &obj); err != nil {
+			panic(err.Error())
+		}
+		// CfnModel acepta el schema tal cual; awsapigateway.Model exigiría un JsonSchema tipado
+		id := fmt.Sprintf("RequestModel%s", hash[:12])
+		cfnModel := awsapigateway.NewCfnModel(v.scope, jsii.String(id), &awsapigateway.CfnModelProps{
+			RestApiId:   v.api.RestApiId(),
+			ContentType: jsii.String("application/json"),
+			Schema:      obj,
+		})
+		model = awsapigateway.Model_FromModelName(v.scope, jsii.String(id+"Ref"), cfnModel.Ref())
+		v.models[hash] = model
+	}
+	return &map[string]awsapigateway.IModel{"application/json": model}
+}
@@ -0,0 +1,96 @@
+// internal/progress/progress.go
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event is a single newline-delimited JSON progress record
+type Event struct {
+	Phase      string `json:"phase"`
+	Function   string `json:"function,omitempty"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"durationMs,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Event statuses
+const (
+	StatusStarted   = "started"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+var (
+	mu      sync.Mutex
+	encoder *json.Encoder // nil when JSON events are disabled
+)
+
+// EnableJSON switches to JSON events on w and silences the human-readable log
+func EnableJSON(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	encoder = json.NewEncoder(w)
+	log.SetOutput(io.Discard)
+}
+
+// Enabled reports whether JSON events are being emitted
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return encoder != nil
+}
+
+// Stdout returns where subprocess output should go so it never
+// interleaves with the JSON event stream
+func Stdout() io.Writer {
+	if Enabled() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// Emit writes an event when JSON events are enabled
+func Emit(ev Event) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if encoder != nil {
+		encoder.Encode(ev)
+	}
+}
+
+// Step tracks a timed phase (build/synth/deploy...) for one function or the whole service
+type Step struct {
+	phase    string
+	function string
+	start    time.Time
+}
+
+// Start emits a "started" event and returns the step to finish with Done
+func Start(phase, function string) *Step {
+	Emit(Event{Phase: phase, Function: function, Status: StatusStarted})
+	return &Step{phase: phase, function: function, start: time.Now()}
+}
+
+// Done emits "succeeded" or "failed" with the elapsed time and returns err unchanged
+func (s *Step) Done(err error) error {
+	ev := Event{
+		Phase:      s.phase,
+		Function:   s.function,
+		Status:     StatusSucceeded,
+		DurationMs: time.Since(s.start).Milliseconds(),
+	}
+	if err != nil {
+		ev.Status = StatusFailed
+		ev.Error = err.Error()
+	}
+	Emit(ev)
+	return err
+}
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NormAPIPath normaliza una ruta de API Gateway: "/" inicial, sin "/" final ni dobles
+func NormAPIPath(p string) string {
+	s := "/" + strings.Trim(strings.ReplaceAll(p, "\\", "/"), "/")
+	for strings.Contains(s, "//") { // "///" deja "//" tras un solo ReplaceAll
+		s = strings.ReplaceAll(s, "//", "/")
+	}
+	return s
+}
+
+// JoinAPIPath concatena resource + path manejando "/", "", etc.
+func JoinAPIPath(resource, path string) string {
+	r := strings.TrimSpace(resource)
+	p := strings.TrimSpace(path)
+
+	switch {
+	case r == "" || r == "/":
+		return NormAPIPath(p)
+	case p == "" || p == "/":
+		return NormAPIPath(r)
+	default:
+		return NormAPIPath(r + "/" + strings.TrimPrefix(p, "/"))
+	}
+}
+
+// Segmento literal o parámetro {name} / {name+}
+var reAPISegment = regexp.MustCompile(`^([A-Za-z0-9._~:@-]+|\{[A-Za-z0-9_]+\+?\})$`)
+
+// ValidateAPIPath comprueba que una ruta ya normalizada sea legal en API Gateway
+func ValidateAPIPath(p string) error {
+	if p == "/" {
+		return nil
+	}
+
+	segments := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, seg := range segments {
+		if !reAPISegment.MatchString(seg) {
+			return fmt.Errorf("invalid path segment '%s' in '%s'", seg, p)
+		}
+		if strings.HasSuffix(seg, "+}") && i != len(segments)-1 {
+			return fmt.Errorf("greedy parameter '%s' must be the last segment of '%s'", seg, p)
+		}
+	}
+	return nil
+}
+
+// GreedyPrefix devuelve el prefijo que cubre una ruta con {proxy+} final
+// ("/files/{proxy+}" -> "/files"); ok es false si la ruta no es greedy
+func GreedyPrefix(p string) (prefix string, ok bool) {
+	p = NormAPIPath(p)
+	i := strings.LastIndex(p, "/")
+	if last := p[i+1:]; !strings.HasPrefix(last, "{") || !strings.HasSuffix(last, "+}") {
+		return "", false
+	}
+	return NormAPIPath(p[:i]), true
+}
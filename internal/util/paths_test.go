@@ -0,0 +1,45 @@
+package util
+
+import "testing"
+
+func TestJoinAPIPath(t *testing.T) {
+	tests := []struct {
+		resource, path, want string
+	}{
+		{"", "", "/"},
+		{"/", "/", "/"},
+		{"", "/users", "/users"},
+		{"/", "users", "/users"},
+		{"/api", "", "/api"},
+		{"/api", "/", "/api"},
+		{"/api", "/users", "/api/users"},
+		{"/api/", "/users", "/api/users"},
+		{"api", "users", "/api/users"},
+		{"/api/", "/users/", "/api/users"},
+		{"/api//v1", "//users", "/api/v1/users"},
+		{" /api ", " /users ", "/api/users"},
+		{"\\api", "\\users\\{id}", "/api/users/{id}"},
+		{"/files", "/{proxy+}", "/files/{proxy+}"},
+	}
+	for _, tt := range tests {
+		if got := JoinAPIPath(tt.resource, tt.path); got != tt.want {
+			t.Errorf("JoinAPIPath(%q, %q) = %q, want %q", tt.resource, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNormAPIPath(t *testing.T) {
+	tests := map[string]string{
+		"":            "/",
+		"/":           "/",
+		"users":       "/users",
+		"/users/":     "/users",
+		"//users//id": "/users/id",
+		"/a///b":      "/a/b",
+	}
+	for in, want := range tests {
+		if got := NormAPIPath(in); got != want {
+			t.Errorf("NormAPIPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -84,3 +84,26 @@ func CopyCode(sourcePath, targetDir string) error {
 
 	return nil
 }
+
+// CopyDir copia recursivamente srcDir en dstDir, ignorando directorios ocultos y cachés
+func CopyDir(srcDir, dstDir string) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if rel != "." && (strings.HasPrefix(info.Name(), ".") || info.Name() == "__pycache__") {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dstDir, rel), 0755)
+		}
+
+		return CopyCode(path, filepath.Join(dstDir, filepath.Dir(rel)))
+	})
+}
@@ -1,8 +1,64 @@
 package util
 
-import "strings"
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
 
 // Reemplaza ${stage} por el valor real
 func ResolveVars(s, stage string) string {
 	return strings.ReplaceAll(s, "${stage}", stage)
 }
+
+// Captura cualquier ${...}; las referencias desconocidas se dejan intactas
+// (p. ej. ${AWS::Region} de CloudFormation)
+var reVar = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// Interpolate resuelve ${stage}, ${self:<campo>}, ${env:<VAR>} y ${env:<VAR>, default} en s.
+// self contiene los valores de nivel superior de la configuración (service, stage, ...)
+func Interpolate(s string, self map[string]string) (string, error) {
+	var firstErr error
+
+	out := reVar.ReplaceAllStringFunc(s, func(token string) string {
+		expr := strings.TrimSpace(token[2 : len(token)-1])
+
+		switch {
+		case expr == "stage":
+			return self["stage"]
+		case strings.HasPrefix(expr, "self:"):
+			key := strings.TrimSpace(strings.TrimPrefix(expr, "self:"))
+			if v, ok := self[key]; ok {
+				return v
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("unknown self reference '%s'", token)
+			}
+		case strings.HasPrefix(expr, "env:"):
+			// ${env:VAR, default}: el default se usa si VAR no está definida
+			name, fallback, hasDefault := strings.Cut(strings.TrimPrefix(expr, "env:"), ",")
+			name = strings.TrimSpace(name)
+			if v, ok := os.LookupEnv(name); ok {
+				return v
+			}
+			if hasDefault {
+				return unquote(strings.TrimSpace(fallback))
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("environment variable '%s' is not set", name)
+			}
+		}
+		return token
+	})
+
+	return out, firstErr
+}
+
+// unquote quita las comillas simples o dobles que rodean un default
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
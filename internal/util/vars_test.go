@@ -0,0 +1,38 @@
+package util
+
+import "testing"
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("QRIOSLS_TEST_TABLE", "orders")
+	t.Setenv("QRIOSLS_TEST_EMPTY", "")
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{in: "${env:QRIOSLS_TEST_TABLE}", want: "orders"},
+		{in: "${env:QRIOSLS_TEST_TABLE, fallback}", want: "orders"},
+		{in: "${env:QRIOSLS_TEST_EMPTY, fallback}", want: ""},
+		{in: "${env:QRIOSLS_TEST_UNSET, fallback}", want: "fallback"},
+		{in: "${env:QRIOSLS_TEST_UNSET,fallback}", want: "fallback"},
+		{in: "${env:QRIOSLS_TEST_UNSET, 'two words'}", want: "two words"},
+		{in: `${env:QRIOSLS_TEST_UNSET, "a,b"}`, want: "a,b"},
+		{in: "${env:QRIOSLS_TEST_UNSET, ''}", want: ""},
+		{in: "${env:QRIOSLS_TEST_UNSET,}", want: ""},
+		{in: "table-${env:QRIOSLS_TEST_UNSET, dev}-${stage}", want: "table-dev-qa"},
+		{in: "${env:QRIOSLS_TEST_UNSET}", wantErr: "environment variable 'QRIOSLS_TEST_UNSET' is not set"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.in, map[string]string{"stage": "qa"})
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Interpolate(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}